@@ -2,6 +2,9 @@ package cryptopals
 
 import (
 	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"fmt"
 )
 
 func DecryptAESECB(cipher, key []byte) ([]byte, error) {
@@ -30,13 +33,30 @@ func DecryptAESCBC(cipher, key, iv []byte) ([]byte, error) {
 	return decrypted, nil
 }
 
+// Decrypts and strips the padding, returning an error if it's invalid
+func DecryptAESCBCWithPadding(cipher, key, iv []byte, padding Padding) ([]byte, error) {
+	decrypted, err := DecryptAESCBC(cipher, key, iv)
+	if err != nil {
+		return []byte{}, err
+	}
+	return padding.Unpad(decrypted, aes.BlockSize)
+}
+
 func EncryptAESCBC(data, key, iv []byte) ([]byte, error) {
+	return EncryptAESCBCWithPadding(data, key, iv, PKCS7)
+}
+
+// Pads data with the given scheme before encrypting it
+func EncryptAESCBCWithPadding(data, key, iv []byte, padding Padding) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return []byte{}, err
 	}
 
-	data = PKCS7Pad(len(key), data)
+	data, err = padding.Pad(data, aes.BlockSize)
+	if err != nil {
+		return []byte{}, err
+	}
 	blockMode := NewCBCEncrypter(block, iv)
 	encrypted := make([]byte, len(data))
 	blockMode.CryptBlocks(encrypted, data)
@@ -45,12 +65,20 @@ func EncryptAESCBC(data, key, iv []byte) ([]byte, error) {
 }
 
 func EncryptAESECB(data, key []byte) ([]byte, error) {
+	return EncryptAESECBWithPadding(data, key, PKCS7)
+}
+
+// Pads data with the given scheme before encrypting it
+func EncryptAESECBWithPadding(data, key []byte, padding Padding) ([]byte, error) {
 	block, err := aes.NewCipher(key)
 	if err != nil {
 		return []byte{}, err
 	}
 
-	data = PKCS7Pad(len(key), data)
+	data, err = padding.Pad(data, aes.BlockSize)
+	if err != nil {
+		return []byte{}, err
+	}
 	blockMode := NewECBEncrypter(block)
 	encrypted := make([]byte, len(data))
 	blockMode.CryptBlocks(encrypted, data)
@@ -64,9 +92,97 @@ func AESCTR(data, key []byte, iv int) ([]byte, error) {
 		return []byte{}, err
 	}
 
-	blockMode := NewCTR(block, iv)
+	stream := NewCTR(block, iv)
+	result := make([]byte, len(data))
+	stream.XORKeyStream(result, data)
+
+	return result, nil
+}
+
+// Runs a block mode over data which is already a whole number of blocks,
+// with an IV of ivSize bytes
+func cryptAESBlocks(data, key, iv []byte, ivSize int, mode func(cipher.Block, []byte) cipher.BlockMode) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+	if len(iv) != ivSize {
+		return []byte{}, fmt.Errorf("IV must be %d bytes", ivSize)
+	}
+	if len(data)%aes.BlockSize != 0 {
+		return []byte{}, errors.New("Input must be a whole number of blocks")
+	}
+
+	blockMode := mode(block, iv)
 	result := make([]byte, len(data))
 	blockMode.CryptBlocks(result, data)
 
 	return result, nil
 }
+
+// Runs a stream mode over data
+func cryptAESStream(data, key, iv []byte, mode func(cipher.Block, []byte) cipher.Stream) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	stream := mode(block, iv)
+	result := make([]byte, len(data))
+	stream.XORKeyStream(result, data)
+
+	return result, nil
+}
+
+func EncryptAESPCBC(data, key, iv []byte) ([]byte, error) {
+	data, err := PKCS7.Pad(data, aes.BlockSize)
+	if err != nil {
+		return []byte{}, err
+	}
+	return cryptAESBlocks(data, key, iv, aes.BlockSize, NewPCBCEncrypter)
+}
+
+// Like DecryptAESCBC, the result is still padded
+func DecryptAESPCBC(cipher, key, iv []byte) ([]byte, error) {
+	return cryptAESBlocks(cipher, key, iv, aes.BlockSize, NewPCBCDecrypter)
+}
+
+// The IV is two blocks long
+func EncryptAESIGE(data, key, iv []byte) ([]byte, error) {
+	data, err := PKCS7.Pad(data, aes.BlockSize)
+	if err != nil {
+		return []byte{}, err
+	}
+	return cryptAESBlocks(data, key, iv, 2*aes.BlockSize, NewIGEEncrypter)
+}
+
+// Like DecryptAESCBC, the result is still padded
+func DecryptAESIGE(cipher, key, iv []byte) ([]byte, error) {
+	return cryptAESBlocks(cipher, key, iv, 2*aes.BlockSize, NewIGEDecrypter)
+}
+
+// The stream modes don't need any padding
+
+func EncryptAESCFB(data, key, iv []byte) ([]byte, error) {
+	return cryptAESStream(data, key, iv, NewCFBEncrypter)
+}
+
+func DecryptAESCFB(cipher, key, iv []byte) ([]byte, error) {
+	return cryptAESStream(cipher, key, iv, NewCFBDecrypter)
+}
+
+func EncryptAESCFB8(data, key, iv []byte) ([]byte, error) {
+	return cryptAESStream(data, key, iv, NewCFB8Encrypter)
+}
+
+func DecryptAESCFB8(cipher, key, iv []byte) ([]byte, error) {
+	return cryptAESStream(cipher, key, iv, NewCFB8Decrypter)
+}
+
+func EncryptAESOFB(data, key, iv []byte) ([]byte, error) {
+	return cryptAESStream(data, key, iv, NewOFB)
+}
+
+func DecryptAESOFB(cipher, key, iv []byte) ([]byte, error) {
+	return cryptAESStream(cipher, key, iv, NewOFB)
+}
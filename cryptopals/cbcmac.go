@@ -0,0 +1,21 @@
+package cryptopals
+
+import (
+	"crypto/aes"
+)
+
+// Computes the AES-CBC-MAC of `msg`. The message is PKCS#7 padded, encrypted
+// in CBC mode and the last block of ciphertext is the MAC.
+func CBCMAC(key, iv, msg []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	data := PKCS7Pad(block.BlockSize(), append([]byte{}, msg...))
+	blockMode := NewCBCEncrypter(block, iv)
+	encrypted := make([]byte, len(data))
+	blockMode.CryptBlocks(encrypted, data)
+
+	return encrypted[len(encrypted)-block.BlockSize():], nil
+}
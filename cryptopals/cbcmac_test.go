@@ -0,0 +1,29 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestCBCMAC(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	iv := make([]byte, aes.BlockSize)
+	msg := []byte("alert('MZA who was that?');\n")
+
+	mac, err := CBCMAC(key, iv, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "296b8d7cb78a243dda4d0a61d33bbdd1"
+	if result := hex.EncodeToString(mac); result != expected {
+		t.Errorf("Incorrect MAC.\nExpected:\t%s\nGot:\t\t%s", expected, result)
+	}
+
+	encrypted, _ := EncryptAESCBC(msg, key, iv)
+	if !bytes.Equal(mac, encrypted[len(encrypted)-aes.BlockSize:]) {
+		t.Errorf("MAC does not match the last block of the CBC ciphertext")
+	}
+}
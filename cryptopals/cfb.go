@@ -0,0 +1,111 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+)
+
+// Cipher feedback mode. Each block of keystream is the encryption of the
+// previous ciphertext block, so it's a stream cipher which only needs the
+// block cipher's encrypt direction.
+
+type cfb struct {
+	b         cipher.Block
+	blockSize int
+	// The ciphertext block being built, which feeds the next keystream block
+	next      []byte
+	keystream []byte
+	used      int
+	decrypt   bool
+}
+
+func newCFB(b cipher.Block, iv []byte, decrypt bool) *cfb {
+	x := &cfb{
+		b:         b,
+		blockSize: b.BlockSize(),
+		next:      make([]byte, b.BlockSize()),
+		keystream: make([]byte, b.BlockSize()),
+		decrypt:   decrypt,
+	}
+	b.Encrypt(x.keystream, iv)
+	return x
+}
+
+func NewCFBEncrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB(b, iv, false)
+}
+
+func NewCFBDecrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB(b, iv, true)
+}
+
+func (x *cfb) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	for i := range src {
+		if x.used == x.blockSize {
+			x.b.Encrypt(x.keystream, x.next)
+			x.used = 0
+		}
+
+		// The ciphertext byte is the input when decrypting and the output
+		// when encrypting. Save it before writing dst, in case dst and src
+		// are the same.
+		in := src[i]
+		dst[i] = in ^ x.keystream[x.used]
+		if x.decrypt {
+			x.next[x.used] = in
+		} else {
+			x.next[x.used] = dst[i]
+		}
+		x.used++
+	}
+}
+
+// CFB-8 shifts a single byte of ciphertext into the register at a time, and
+// only uses the first byte of each encryption. That's a block cipher call per
+// byte, but a flipped ciphertext bit only garbles the next block's worth of
+// plaintext.
+
+type cfb8 struct {
+	b        cipher.Block
+	register []byte
+	out      []byte
+	decrypt  bool
+}
+
+func newCFB8(b cipher.Block, iv []byte, decrypt bool) *cfb8 {
+	return &cfb8{
+		b:        b,
+		register: append([]byte{}, iv...),
+		out:      make([]byte, b.BlockSize()),
+		decrypt:  decrypt,
+	}
+}
+
+func NewCFB8Encrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(b, iv, false)
+}
+
+func NewCFB8Decrypter(b cipher.Block, iv []byte) cipher.Stream {
+	return newCFB8(b, iv, true)
+}
+
+func (x *cfb8) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	for i := range src {
+		x.b.Encrypt(x.out, x.register)
+
+		in := src[i]
+		dst[i] = in ^ x.out[0]
+
+		c := dst[i]
+		if x.decrypt {
+			c = in
+		}
+		copy(x.register, x.register[1:])
+		x.register[len(x.register)-1] = c
+	}
+}
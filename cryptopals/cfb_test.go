@@ -0,0 +1,78 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestCFBMatchesStdlib(t *testing.T) {
+	key, iv := randomBytes(t, 16), randomBytes(t, 16)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := randomBytes(t, 77)
+
+	expected := make([]byte, len(plaintext))
+	cipher.NewCFBEncrypter(block, iv).XORKeyStream(expected, plaintext)
+
+	// In uneven pieces, to cross block boundaries mid-call
+	encrypted := make([]byte, len(plaintext))
+	stream := NewCFBEncrypter(block, iv)
+	for _, span := range [][2]int{{0, 3}, {3, 20}, {20, 77}} {
+		stream.XORKeyStream(encrypted[span[0]:span[1]], plaintext[span[0]:span[1]])
+	}
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+
+	decrypted, err := DecryptAESCFB(encrypted, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %x, got %x", plaintext, decrypted)
+	}
+}
+
+func TestCFBInPlace(t *testing.T) {
+	key, iv := randomBytes(t, 16), randomBytes(t, 16)
+	plaintext := randomBytes(t, 40)
+
+	encrypted, err := EncryptAESCFB(plaintext, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, _ := aes.NewCipher(key)
+	buf := append([]byte{}, encrypted...)
+	NewCFBDecrypter(block, iv).XORKeyStream(buf, buf)
+	if !bytes.Equal(buf, plaintext) {
+		t.Errorf("Expected %x, got %x", plaintext, buf)
+	}
+}
+
+func TestCFB8SP800_38A(t *testing.T) {
+	// F.3.7 CFB8-AES128.Encrypt
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	plaintext := decodeHex(t, "6bc1bee22e409f96e93d7e117393172aae2d")
+	expected := decodeHex(t, "3b79424c9c0dd436bace9e0ed4586a4f32b9")
+
+	encrypted, err := EncryptAESCFB8(plaintext, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+
+	decrypted, err := DecryptAESCFB8(encrypted, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %x, got %x", plaintext, decrypted)
+	}
+}
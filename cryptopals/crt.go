@@ -0,0 +1,78 @@
+package cryptopals
+
+import (
+	"errors"
+	"math/big"
+)
+
+// Solves the system of congruences x = residues[i] mod moduli[i] with the
+// Chinese Remainder Theorem. The moduli must be pairwise coprime.
+//
+// Returns the unique solution x in [0, N) along with N, the product of the
+// moduli.
+func CRT(residues, moduli []*big.Int) (*big.Int, *big.Int, error) {
+	if len(residues) != len(moduli) || len(moduli) == 0 {
+		return nil, nil, errors.New("CRT needs one residue for each modulus")
+	}
+
+	N := big.NewInt(1)
+	for _, m := range moduli {
+		N.Mul(N, m)
+	}
+
+	result := big.NewInt(0)
+	for i, m := range moduli {
+		// Ms = N / m, and its inverse mod m
+		Ms := new(big.Int).Div(N, m)
+		inv := new(big.Int).ModInverse(Ms, m)
+		if inv == nil {
+			return nil, nil, errors.New("CRT moduli must be pairwise coprime")
+		}
+
+		term := new(big.Int).Mul(residues[i], Ms)
+		term.Mul(term, inv)
+		result.Add(result, term)
+	}
+
+	return result.Mod(result, N), N, nil
+}
+
+// Solves the system of congruences x = residues[i] mod moduli[i], where the
+// moduli don't need to be coprime. Congruences are merged pairwise: there's a
+// solution to x = a1 mod m1 and x = a2 mod m2 when a1 = a2 mod gcd(m1, m2),
+// and it's unique mod lcm(m1, m2).
+//
+// Returns the unique solution x in [0, N) along with N, the lcm of the moduli.
+func GeneralizedCRT(residues, moduli []*big.Int) (*big.Int, *big.Int, error) {
+	if len(residues) != len(moduli) || len(moduli) == 0 {
+		return nil, nil, errors.New("CRT needs one residue for each modulus")
+	}
+
+	x := new(big.Int).Mod(residues[0], moduli[0])
+	N := new(big.Int).Set(moduli[0])
+
+	for i := 1; i < len(moduli); i++ {
+		m := moduli[i]
+		g := new(big.Int).GCD(nil, nil, N, m)
+
+		// a2 - x must be divisible by g
+		diff := new(big.Int).Sub(residues[i], x)
+		diff, rem := diff.DivMod(diff, g, new(big.Int))
+		if rem.Sign() != 0 {
+			return nil, nil, errors.New("CRT congruences are inconsistent")
+		}
+
+		// x += N * (diff * (N/g)^-1 mod m/g)
+		mg := new(big.Int).Div(m, g)
+		inv := new(big.Int).Div(N, g)
+		inv.ModInverse(inv, mg)
+		k := diff.Mul(diff, inv)
+		k.Mod(k, mg)
+
+		x.Add(x, k.Mul(k, N))
+		N.Mul(N, mg)
+		x.Mod(x, N)
+	}
+
+	return x, N, nil
+}
@@ -0,0 +1,77 @@
+package cryptopals
+
+import (
+	"math/big"
+	"testing"
+)
+
+func bigs(values ...int64) []*big.Int {
+	var result []*big.Int
+	for _, v := range values {
+		result = append(result, big.NewInt(v))
+	}
+	return result
+}
+
+func TestCRT(t *testing.T) {
+	// x = 2 mod 3, x = 3 mod 5, x = 2 mod 7
+	x, N, err := CRT(bigs(2, 3, 2), bigs(3, 5, 7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Int64() != 23 || N.Int64() != 105 {
+		t.Errorf("Expected 23 mod 105, got %d mod %d", x, N)
+	}
+}
+
+func TestCRTSingleModulus(t *testing.T) {
+	x, N, err := CRT(bigs(12), bigs(7))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Int64() != 5 || N.Int64() != 7 {
+		t.Errorf("Expected 5 mod 7, got %d mod %d", x, N)
+	}
+}
+
+func TestCRTErrors(t *testing.T) {
+	if _, _, err := CRT(bigs(1, 2), bigs(4, 6)); err == nil {
+		t.Error("Expected an error for moduli which aren't coprime")
+	}
+	if _, _, err := CRT(bigs(1), bigs(4, 5)); err == nil {
+		t.Error("Expected an error for mismatched residues and moduli")
+	}
+	if _, _, err := CRT(nil, nil); err == nil {
+		t.Error("Expected an error for no moduli")
+	}
+}
+
+func TestGeneralizedCRT(t *testing.T) {
+	// x = 3 mod 4, x = 5 mod 6, x = 2 mod 9: 11 mod 36
+	x, N, err := GeneralizedCRT(bigs(3, 5, 2), bigs(4, 6, 9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Int64() != 11 || N.Int64() != 36 {
+		t.Errorf("Expected 11 mod 36, got %d mod %d", x, N)
+	}
+}
+
+func TestGeneralizedCRTMatchesCRT(t *testing.T) {
+	x, N, err := GeneralizedCRT(bigs(2, 3, 2, 2), bigs(3, 5, 7, 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if x.Int64() != 23 || N.Int64() != 105 {
+		t.Errorf("Expected 23 mod 105, got %d mod %d", x, N)
+	}
+}
+
+func TestGeneralizedCRTErrors(t *testing.T) {
+	if _, _, err := GeneralizedCRT(bigs(1, 2), bigs(4, 6)); err == nil {
+		t.Error("Expected an error for inconsistent congruences")
+	}
+	if _, _, err := GeneralizedCRT(bigs(1), bigs(4, 5)); err == nil {
+		t.Error("Expected an error for mismatched residues and moduli")
+	}
+}
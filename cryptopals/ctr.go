@@ -1,102 +1,192 @@
 package cryptopals
 
 import (
-	"bytes"
 	"crypto/cipher"
 	"encoding/binary"
+	"errors"
 )
 
 // Go provides a CTR block mode, but there's no fun in using that
 
+// How the nonce and counter are packed into each counter block
+type CounterLayout int
+
+const (
+	// A 64-bit little endian nonce, then a 64-bit little endian block count,
+	// as in challenge 18
+	CounterLE64 CounterLayout = iota
+	// The whole block is a 128-bit big endian counter, as in NIST SP 800-38A
+	// and crypto/cipher's NewCTR
+	CounterBE128
+	// A 96-bit nonce, then a 32-bit big endian counter, as in GCM
+	CounterNonce96
+)
+
+var ErrCounterOverflow = errors.New("CTR counter overflowed")
+
 type ctr struct {
 	b         cipher.Block
 	blockSize int
-	iv        int
-	counter   int
+	layout    CounterLayout
+	// The first counter block
+	iv []byte
+	// The number of blocks since iv, and how many bytes of that block's
+	// keystream we've used up
+	counter   uint64
+	used      int
+	keystream []byte
 }
 
+// A CTR stream with the challenge 18 layout, starting from `iv` as the nonce
+// and a zero block count
 func NewCTR(b cipher.Block, iv int) *ctr {
+	initial := make([]byte, b.BlockSize())
+	binary.LittleEndian.PutUint64(initial, uint64(iv))
+	x, err := NewCTRWithLayout(b, initial, CounterLE64)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// A CTR stream whose first counter block is `iv`, counting up according to
+// `layout`
+func NewCTRWithLayout(b cipher.Block, iv []byte, layout CounterLayout) (*ctr, error) {
+	if b.BlockSize() != 16 {
+		return nil, errors.New("CTR counter layouts require a 128-bit block cipher")
+	}
+	if len(iv) != b.BlockSize() {
+		return nil, errors.New("IV length must equal the block size")
+	}
+	if layout < CounterLE64 || layout > CounterNonce96 {
+		return nil, errors.New("Unknown CTR counter layout")
+	}
 	return &ctr{
 		b:         b,
 		blockSize: b.BlockSize(),
-		iv:        iv,
-	}
+		layout:    layout,
+		iv:        append([]byte{}, iv...),
+	}, nil
 }
 
 func (x *ctr) BlockSize() int { return x.blockSize }
 
-func (x *ctr) Nonce() []byte {
-	iv := &bytes.Buffer{}
-	counter := &bytes.Buffer{}
+// Builds the counter block `n` blocks after the IV, or returns
+// ErrCounterOverflow if the counter would wrap around
+func (x *ctr) counterBlock(n uint64) ([]byte, error) {
+	block := append([]byte{}, x.iv...)
 
-	binary.Write(iv, binary.LittleEndian, uint64(x.iv))
-	binary.Write(counter, binary.LittleEndian, uint64(x.counter))
+	switch x.layout {
+	case CounterLE64:
+		start := binary.LittleEndian.Uint64(block[8:])
+		if start+n < start {
+			return nil, ErrCounterOverflow
+		}
+		binary.LittleEndian.PutUint64(block[8:], start+n)
+	case CounterBE128:
+		hi, lo := binary.BigEndian.Uint64(block[:8]), binary.BigEndian.Uint64(block[8:])
+		if lo+n < lo {
+			if hi+1 == 0 {
+				return nil, ErrCounterOverflow
+			}
+			hi++
+		}
+		binary.BigEndian.PutUint64(block[:8], hi)
+		binary.BigEndian.PutUint64(block[8:], lo+n)
+	case CounterNonce96:
+		start := uint64(binary.BigEndian.Uint32(block[12:]))
+		if start+n > 0xffffffff {
+			return nil, ErrCounterOverflow
+		}
+		binary.BigEndian.PutUint32(block[12:], uint32(start+n))
+	}
 
-	return append(iv.Bytes(), counter.Bytes()...)
+	return block, nil
 }
 
-// Generate specific bytes of the keystream
-// Returns `length` bytes at `offset` from the keystream
-func (x *ctr) KeystreamBytes(offset, length int) []byte {
-	var result bytes.Buffer
-	// Save and restore the original counter
-	// This isn't thread safe but idgaf
-	currCounter := x.counter
-	defer func() {
-		x.counter = currCounter
-	}()
-
-	if offset < 0 || length < 1 {
-		panic("ctr.KeyStreamRange: offset must be >= 0 and length must be >= 1")
+// The counter block for the current position in the stream
+func (x *ctr) Nonce() []byte {
+	block, err := x.counterBlock(x.counter)
+	if err != nil {
+		panic(err)
 	}
+	return block
+}
 
-	x.counter = offset / x.blockSize
+// The current position in the keystream, in bytes
+func (x *ctr) Offset() uint64 {
+	return x.counter*uint64(x.blockSize) + uint64(x.used)
+}
 
-	// Generate 1 more block than the length to make sure we have enough
-	// bytes to trim off afterwards.
-	for result.Len() < (length + x.BlockSize()) {
-		nonce := x.Nonce()
-		encryptedNonce := make([]byte, x.blockSize)
-		x.b.Encrypt(encryptedNonce, nonce)
-		result.Write(encryptedNonce)
-		x.counter++
+// Moves to `offset` bytes into the keystream. Only the block at the new
+// offset is ever generated, so seeking is cheap.
+func (x *ctr) Seek(offset uint64) error {
+	counter := offset / uint64(x.blockSize)
+	if _, err := x.counterBlock(counter); err != nil {
+		return err
 	}
-
-	// Trim keystream
-	start := offset % x.blockSize
-	end := start + length
-
-	return result.Bytes()[start:end]
+	x.counter = counter
+	x.used = int(offset % uint64(x.blockSize))
+	x.keystream = nil
+	return nil
 }
 
-func (x *ctr) CryptBlocks(dst, src []byte) {
+// XORs src with the keystream into dst, implementing cipher.Stream. Like the
+// stdlib, it panics when dst is too short or the counter overflows.
+func (x *ctr) XORKeyStream(dst, src []byte) {
 	if len(dst) < len(src) {
 		panic("crypto/cipher: output smaller than input")
 	}
 
-	copy(dst, src)
-
 	for len(src) > 0 {
-		nonce := x.Nonce()
-		encryptedNonce := make([]byte, x.blockSize)
-		x.b.Encrypt(encryptedNonce, nonce)
-
-		if len(src) < x.blockSize {
-			err := FixedXOR(dst[:len(src)], encryptedNonce[:len(src)])
-			if err != nil {
-				panic(err)
-			}
-			// Empty src so the loop finishes
-			src = []byte{}
-		} else {
-			err := FixedXOR(dst[:x.blockSize], encryptedNonce)
-			if err != nil {
-				panic(err)
-			}
+		if x.used == x.blockSize {
+			x.counter++
+			x.used = 0
+			x.keystream = nil
+		}
+		if x.keystream == nil {
+			x.keystream = make([]byte, x.blockSize)
+			x.b.Encrypt(x.keystream, x.Nonce())
+		}
 
-			src = src[x.blockSize:]
-			dst = dst[x.blockSize:]
-			x.counter += 1
+		n := x.blockSize - x.used
+		if len(src) < n {
+			n = len(src)
+		}
+		for i := 0; i < n; i++ {
+			dst[i] = src[i] ^ x.keystream[x.used+i]
 		}
+
+		x.used += n
+		src = src[n:]
+		dst = dst[n:]
 	}
 }
+
+// Generate specific bytes of the keystream
+// Returns `length` bytes at `offset` from the keystream
+func (x *ctr) KeystreamBytes(offset, length int) []byte {
+	if offset < 0 || length < 1 {
+		panic("ctr.KeyStreamRange: offset must be >= 0 and length must be >= 1")
+	}
+
+	// Save and restore the original position
+	// This isn't thread safe but idgaf
+	counter, used, keystream := x.counter, x.used, x.keystream
+	defer func() {
+		x.counter, x.used, x.keystream = counter, used, keystream
+	}()
+
+	if err := x.Seek(uint64(offset)); err != nil {
+		panic(err)
+	}
+	result := make([]byte, length)
+	x.XORKeyStream(result, result)
+	return result
+}
+
+// CTR is a stream cipher, but we've been using it like a cipher.BlockMode
+// since challenge 18
+func (x *ctr) CryptBlocks(dst, src []byte) {
+	x.XORKeyStream(dst, src)
+}
@@ -3,6 +3,8 @@ package cryptopals
 import (
 	"bytes"
 	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
 	"testing"
 )
 
@@ -82,3 +84,142 @@ func TestCTRKeystreamByteseSingleByte(t *testing.T) {
 		t.Errorf("Invalid keystream.\nExpected:\t%v\nGot:\t\t%v", expected, keystream)
 	}
 }
+
+var _ cipher.Stream = (*ctr)(nil)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestCTRSP800_38A(t *testing.T) {
+	// F.5.1 CTR-AES128.Encrypt
+	key := decodeHex(t, "2b7e151628aed2a6abf7158809cf4f3c")
+	iv := decodeHex(t, "f0f1f2f3f4f5f6f7f8f9fafbfcfdfeff")
+	plaintext := decodeHex(t, "6bc1bee22e409f96e93d7e117393172a"+
+		"ae2d8a571e03ac9c9eb76fac45af8e51"+
+		"30c81c46a35ce411e5fbc1191a0a52ef"+
+		"f69f2445df4f9b17ad2b417be66c3710")
+	expected := decodeHex(t, "874d6191b620e3261bef6864990db6ce"+
+		"9806f66b7970fdff8617187bb9fffdff"+
+		"5ae4df3edbd5d35e5b4f09020db03eab"+
+		"1e031dda2fbe03d1792170a0f3009cee")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream, err := NewCTRWithLayout(block, iv, CounterBE128)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Feed it in uneven pieces to exercise partial blocks
+	encrypted := make([]byte, len(plaintext))
+	for _, span := range [][2]int{{0, 5}, {5, 16}, {16, 40}, {40, 64}} {
+		stream.XORKeyStream(encrypted[span[0]:span[1]], plaintext[span[0]:span[1]])
+	}
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+}
+
+func TestCTRBE128MatchesStdlib(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The low half carries into the high half after the first block
+	iv := decodeHex(t, "0000000000000000ffffffffffffffff")
+	stream, err := NewCTRWithLayout(block, iv, CounterBE128)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := bytes.Repeat([]byte("A"), 50)
+	expected := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv).XORKeyStream(expected, plaintext)
+
+	encrypted := make([]byte, len(plaintext))
+	stream.XORKeyStream(encrypted, plaintext)
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+}
+
+func TestCTRSeek(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	full := make([]byte, 100)
+	NewCTR(block, 7).XORKeyStream(full, full)
+
+	for _, offset := range []int{0, 1, 15, 16, 33, 99} {
+		stream := NewCTR(block, 7)
+		if err := stream.Seek(uint64(offset)); err != nil {
+			t.Fatal(err)
+		}
+		if stream.Offset() != uint64(offset) {
+			t.Errorf("Expected offset %d, got %d", offset, stream.Offset())
+		}
+		partial := make([]byte, len(full)-offset)
+		stream.XORKeyStream(partial, partial)
+		if !bytes.Equal(partial, full[offset:]) {
+			t.Errorf("Keystream at offset %d doesn't match", offset)
+		}
+	}
+}
+
+func TestCTRCounterOverflow(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, test := range []struct {
+		layout CounterLayout
+		iv     string
+	}{
+		{CounterLE64, "0000000000000000ffffffffffffffff"},
+		{CounterBE128, "ffffffffffffffffffffffffffffffff"},
+		{CounterNonce96, "000000000000000000000000ffffffff"},
+	} {
+		stream, err := NewCTRWithLayout(block, decodeHex(t, test.iv), test.layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The last block before the counter wraps is fine
+		if err := stream.Seek(15); err != nil {
+			t.Errorf("Layout %d: %s", test.layout, err)
+		}
+		if err := stream.Seek(16); err != ErrCounterOverflow {
+			t.Errorf("Layout %d: expected an overflow, got %v", test.layout, err)
+		}
+	}
+}
+
+func TestCTRNonce96(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := decodeHex(t, "cafebabefacedbaddecaf888fffffffe")
+	stream, err := NewCTRWithLayout(block, iv, CounterNonce96)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := stream.Seek(16); err != nil {
+		t.Fatal(err)
+	}
+	expected := decodeHex(t, "cafebabefacedbaddecaf888ffffffff")
+	if nonce := stream.Nonce(); !bytes.Equal(nonce, expected) {
+		t.Errorf("Expected counter block %x, got %x", expected, nonce)
+	}
+}
@@ -0,0 +1,36 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// The parameters for ECDH: a curve, a base point G, and the order N of G
+type Group struct {
+	Curve *Curve
+	G     *Point
+	N     *big.Int
+}
+
+// An ECDH key pair, where Public = D*G
+type PrivateKey struct {
+	Group  *Group
+	D      *big.Int
+	Public *Point
+}
+
+// Generates a key pair with a private key in [1, N)
+func GenerateKey(group *Group) (*PrivateKey, error) {
+	d, err := rand.Int(rand.Reader, new(big.Int).Sub(group.N, big1))
+	if err != nil {
+		return nil, err
+	}
+	d.Add(d, big1)
+	return &PrivateKey{group, d, group.Curve.ScalarMult(group.G, d)}, nil
+}
+
+// Computes the shared secret D*publicKey. Like any good victim, it doesn't
+// check that `publicKey` is on the curve.
+func (k *PrivateKey) SharedSecret(publicKey *Point) *Point {
+	return k.Group.Curve.ScalarMult(publicKey, k.D)
+}
@@ -0,0 +1,90 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+type Signature struct {
+	R, S *big.Int
+}
+
+// Converts a hash to an integer, keeping only as many of its leftmost bits
+// as there are in N, the same way crypto/ecdsa does
+func HashToInt(hash []byte, n *big.Int) *big.Int {
+	bits := n.BitLen()
+	if len(hash) > (bits+7)/8 {
+		hash = hash[:(bits+7)/8]
+	}
+	e := new(big.Int).SetBytes(hash)
+	if excess := len(hash)*8 - bits; excess > 0 {
+		e.Rsh(e, uint(excess))
+	}
+	return e
+}
+
+// Signs `hash` with a random nonce
+func (k *PrivateKey) Sign(hash []byte) (*Signature, error) {
+	n := k.Group.N
+	for {
+		nonce, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big1))
+		if err != nil {
+			return nil, err
+		}
+		nonce.Add(nonce, big1)
+
+		// Start over with a new nonce if either half of the signature is 0
+		sig := k.SignWithNonce(hash, nonce)
+		if sig.R.Sign() != 0 && sig.S.Sign() != 0 {
+			return sig, nil
+		}
+	}
+}
+
+// r = x(k*G) mod n
+// s = k^-1 * (H(m) + d*r) mod n
+func (k *PrivateKey) SignWithNonce(hash []byte, nonce *big.Int) *Signature {
+	n := k.Group.N
+
+	r := new(big.Int).Set(k.Group.Curve.ScalarMult(k.Group.G, nonce).X)
+	r.Mod(r, n)
+
+	s := new(big.Int).Mul(k.D, r)
+	s.Add(s, HashToInt(hash, n))
+	s.Mul(s, new(big.Int).ModInverse(nonce, n))
+	s.Mod(s, n)
+
+	return &Signature{r, s}
+}
+
+// Verifies an ECDSA signature of `hash` under the public key `pub`:
+//
+//	u1 = H(m) * s^-1 mod n
+//	u2 = r * s^-1 mod n
+//	R  = u1*G + u2*Q
+//
+// The signature is valid if x(R) = r mod n
+func Verify(group *Group, pub *Point, hash []byte, sig *Signature) bool {
+	n := group.N
+	if sig.R.Sign() <= 0 || sig.R.Cmp(n) >= 0 {
+		return false
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(n) >= 0 {
+		return false
+	}
+
+	w := new(big.Int).ModInverse(sig.S, n)
+	u1 := new(big.Int).Mul(HashToInt(hash, n), w)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, n)
+
+	c := group.Curve
+	R := c.Add(c.ScalarMult(group.G, u1), c.ScalarMult(pub, u2))
+	if R.IsInfinity() {
+		return false
+	}
+
+	v := new(big.Int).Mod(R.X, n)
+	return v.Cmp(sig.R) == 0
+}
@@ -0,0 +1,77 @@
+package ec
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+)
+
+// The curve and base point from Challenge 59, which is big enough that a
+// forged signature won't verify by chance
+func challengeGroup() *Group {
+	p, _ := new(big.Int).SetString("233970423115425145524320034830162017933", 10)
+	gy, _ := new(big.Int).SetString("85518893674295321206118380980485522083", 10)
+	n, _ := new(big.Int).SetString("29246302889428143187362802287225875743", 10)
+	return &Group{
+		Curve: &Curve{big.NewInt(-95051), big.NewInt(11279326), p},
+		G:     &Point{big.NewInt(182), gy},
+		N:     n,
+	}
+}
+
+func TestHashToInt(t *testing.T) {
+	// Only the top 5 bits of the first byte survive for n = 19
+	if e := HashToInt([]byte{0xff, 0xff}, big.NewInt(19)); e.Int64() != 31 {
+		t.Errorf("Expected 31, got %d", e)
+	}
+	if e := HashToInt([]byte{0x01}, big.NewInt(1<<16)); e.Int64() != 1 {
+		t.Errorf("Expected 1, got %d", e)
+	}
+}
+
+func TestECDSA(t *testing.T) {
+	group := challengeGroup()
+	key, err := GenerateKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte("hello, world"))
+	sig, err := key.Sign(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !Verify(group, key.Public, hash[:], sig) {
+		t.Error("Signature did not verify")
+	}
+
+	other := sha256.Sum256([]byte("goodbye, world"))
+	if Verify(group, key.Public, other[:], sig) {
+		t.Error("Signature verified for the wrong message")
+	}
+
+	bad := &Signature{sig.R, new(big.Int).Add(sig.S, big1)}
+	if Verify(group, key.Public, hash[:], bad) {
+		t.Error("Tampered signature verified")
+	}
+}
+
+func TestSignWithNonce(t *testing.T) {
+	group := challengeGroup()
+	key, err := GenerateKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte("hello, world"))
+	k := big.NewInt(12345)
+	sig := key.SignWithNonce(hash[:], k)
+
+	r := new(big.Int).Mod(group.Curve.ScalarMult(group.G, k).X, group.N)
+	if r.Cmp(sig.R) != 0 {
+		t.Errorf("Expected r = %d, got %d", r, sig.R)
+	}
+	if !Verify(group, key.Public, hash[:], sig) {
+		t.Error("Signature did not verify")
+	}
+}
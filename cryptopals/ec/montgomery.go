@@ -0,0 +1,198 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+// A curve in Montgomery form over GF(P):
+//
+//	B*v^2 = u^3 + A*u^2 + u
+//
+// Points are usually handled by their u coordinate alone. Every u in GF(P) is
+// either on the curve or on its quadratic twist, and the x-only arithmetic
+// here works the same on both.
+type MontgomeryCurve struct {
+	A, B, P *big.Int
+}
+
+// An x-only point in projective coordinates, where u = U/W. The point at
+// infinity has W = 0.
+type XPoint struct {
+	U, W *big.Int
+}
+
+// The x-only point for an affine u coordinate
+func NewXPoint(u *big.Int) XPoint {
+	return XPoint{new(big.Int).Set(u), big.NewInt(1)}
+}
+
+func (p XPoint) IsInfinity() bool {
+	return p.W.Sign() == 0
+}
+
+// (u^3 + A*u^2 + u) / B mod p, which is v^2 for points on the curve
+func (c *MontgomeryCurve) v2(u *big.Int) *big.Int {
+	v2 := new(big.Int).Add(u, c.A)
+	v2.Mul(v2, u)
+	v2.Add(v2, big1)
+	v2.Mul(v2, u)
+	v2.Mul(v2, c.inverse(c.B))
+	return v2.Mod(v2, c.P)
+}
+
+// Finds v for a u coordinate on the curve, or nil if u is on the twist
+func (c *MontgomeryCurve) V(u *big.Int) *big.Int {
+	return new(big.Int).ModSqrt(c.v2(u), c.P)
+}
+
+// Whether u is on the curve rather than its twist
+func (c *MontgomeryCurve) IsOnCurve(u *big.Int) bool {
+	return big.Jacobi(c.v2(u), c.P) >= 0
+}
+
+// Picks a random u coordinate on the curve's quadratic twist
+func (c *MontgomeryCurve) RandomTwistPoint() (*big.Int, error) {
+	for {
+		u, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			return nil, err
+		}
+		if !c.IsOnCurve(u) {
+			return u, nil
+		}
+	}
+}
+
+// Converts projective coordinates back to u = U/W. The point at infinity
+// becomes 0, same as the ladder.
+func (c *MontgomeryCurve) Affine(p XPoint) *big.Int {
+	if p.IsInfinity() {
+		return new(big.Int)
+	}
+	u := new(big.Int).Mul(p.U, c.inverse(p.W))
+	return u.Mod(u, c.P)
+}
+
+// Computes x(2P) from x(P):
+//
+//	U' = (U^2 - W^2)^2
+//	W' = 4*U*W * (U^2 + A*U*W + W^2)
+func (c *MontgomeryCurve) XDouble(p XPoint) XPoint {
+	uu := new(big.Int).Mul(p.U, p.U)
+	ww := new(big.Int).Mul(p.W, p.W)
+	uw := new(big.Int).Mul(p.U, p.W)
+
+	u := new(big.Int).Sub(uu, ww)
+	u.Mul(u, u)
+
+	w := new(big.Int).Mul(c.A, uw)
+	w.Add(w, uu)
+	w.Add(w, ww)
+	w.Mul(w, uw)
+	w.Lsh(w, 2)
+
+	return XPoint{u.Mod(u, c.P), w.Mod(w, c.P)}
+}
+
+// Computes x(P+Q) from x(P), x(Q) and x(P-Q), the differential addition:
+//
+//	U' = W(P-Q) * (U(P)*U(Q) - W(P)*W(Q))^2
+//	W' = U(P-Q) * (U(P)*W(Q) - W(P)*U(Q))^2
+//
+// It doesn't work when P = Q; use XDouble for that.
+func (c *MontgomeryCurve) XAdd(p, q, diff XPoint) XPoint {
+	u := new(big.Int).Mul(p.U, q.U)
+	u.Sub(u, new(big.Int).Mul(p.W, q.W))
+	u.Mul(u, u)
+	u.Mul(u, diff.W)
+
+	w := new(big.Int).Mul(p.U, q.W)
+	w.Sub(w, new(big.Int).Mul(p.W, q.U))
+	w.Mul(w, w)
+	w.Mul(w, diff.U)
+
+	return XPoint{u.Mod(u, c.P), w.Mod(w, c.P)}
+}
+
+// Computes the u coordinate of k*P from the u coordinate of P with the
+// Montgomery ladder. The point at infinity comes back as 0.
+//
+// The ladder keeps R0 = m*P and R1 = (m+1)*P for the prefix m of k it has
+// processed so far, so their difference is always P.
+func (c *MontgomeryCurve) Ladder(u, k *big.Int) *big.Int {
+	r0 := XPoint{big.NewInt(1), big.NewInt(0)}
+	r1 := NewXPoint(u)
+	diff := NewXPoint(u)
+
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		if k.Bit(i) == 0 {
+			r0, r1 = c.XDouble(r0), c.XAdd(r0, r1, diff)
+		} else {
+			r0, r1 = c.XAdd(r0, r1, diff), c.XDouble(r1)
+		}
+	}
+
+	return c.Affine(r0)
+}
+
+// The equivalent curve in short Weierstrass form:
+//
+//	a = (3 - A^2) / (3*B^2)
+//	b = (2*A^3 - 9*A) / (27*B^3)
+func (c *MontgomeryCurve) ToWeierstrass() *Curve {
+	A2 := new(big.Int).Mul(c.A, c.A)
+	B2 := new(big.Int).Mul(c.B, c.B)
+
+	a := new(big.Int).Sub(big3, A2)
+	a.Mul(a, c.inverse(B2.Mul(B2, big3)))
+	a.Mod(a, c.P)
+
+	b := new(big.Int).Mul(A2, c.A)
+	b.Lsh(b, 1)
+	b.Sub(b, new(big.Int).Mul(c.A, big.NewInt(9)))
+	B3 := new(big.Int).Exp(c.B, big3, nil)
+	b.Mul(b, c.inverse(B3.Mul(B3, big.NewInt(27))))
+	b.Mod(b, c.P)
+
+	return &Curve{a, b, new(big.Int).Set(c.P)}
+}
+
+// Maps (u, v) on this curve to the Weierstrass curve:
+//
+//	x = u/B + A/(3*B)
+//	y = v/B
+func (c *MontgomeryCurve) ToWeierstrassPoint(u, v *big.Int) *Point {
+	invB := c.inverse(c.B)
+
+	x := new(big.Int).Mul(c.A, c.inverse(big3))
+	x.Add(x, u)
+	x.Mul(x, invB)
+	x.Mod(x, c.P)
+
+	y := new(big.Int).Mul(v, invB)
+	return &Point{x, y.Mod(y, c.P)}
+}
+
+// Maps a point on the Weierstrass curve back to (u, v):
+//
+//	u = B*x - A/3
+//	v = B*y
+//
+// The point at infinity maps to u = 0, like the ladder.
+func (c *MontgomeryCurve) FromWeierstrassPoint(p *Point) (u, v *big.Int) {
+	if p.IsInfinity() {
+		return new(big.Int), new(big.Int)
+	}
+
+	u = new(big.Int).Mul(c.B, p.X)
+	u.Sub(u, new(big.Int).Mul(c.A, c.inverse(big3)))
+	u.Mod(u, c.P)
+
+	v = new(big.Int).Mul(c.B, p.Y)
+	return u, v.Mod(v, c.P)
+}
+
+func (c *MontgomeryCurve) inverse(n *big.Int) *big.Int {
+	return new(big.Int).ModInverse(new(big.Int).Mod(n, c.P), c.P)
+}
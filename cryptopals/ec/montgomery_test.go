@@ -0,0 +1,148 @@
+package ec
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+const trials = 20
+
+// The curve from Challenge 60, v^2 = u^3 + 534*u^2 + u, and the same curve
+// scaled with B = 5 to make sure B is handled
+func montgomeryCurves() []*MontgomeryCurve {
+	p, _ := new(big.Int).SetString("233970423115425145524320034830162017933", 10)
+	return []*MontgomeryCurve{
+		{big.NewInt(534), big.NewInt(1), p},
+		{big.NewInt(534), big.NewInt(5), p},
+	}
+}
+
+// A random point on the curve, and its Weierstrass form
+func randomMontgomeryPoint(t *testing.T, c *MontgomeryCurve) (*big.Int, *Point) {
+	for {
+		u, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if v := c.V(u); v != nil {
+			return u, c.ToWeierstrassPoint(u, v)
+		}
+	}
+}
+
+// The u coordinate of a point on the Weierstrass form of the curve
+func uOf(c *MontgomeryCurve, p *Point) *big.Int {
+	u, _ := c.FromWeierstrassPoint(p)
+	return u
+}
+
+func TestToWeierstrass(t *testing.T) {
+	c := montgomeryCurves()[0]
+	w := c.ToWeierstrass()
+
+	// Challenge 59's curve: y^2 = x^3 - 95051*x + 11279326
+	a := new(big.Int).Mod(big.NewInt(-95051), c.P)
+	if w.A.Cmp(a) != 0 || w.B.Cmp(big.NewInt(11279326)) != 0 {
+		t.Errorf("Expected a = -95051, b = 11279326, got a = %d, b = %d", w.A, w.B)
+	}
+}
+
+func TestBirationalMap(t *testing.T) {
+	for _, c := range montgomeryCurves() {
+		w := c.ToWeierstrass()
+		for i := 0; i < trials; i++ {
+			u, p := randomMontgomeryPoint(t, c)
+			if !w.IsOnCurve(p) {
+				t.Fatalf("u = %d maps to a point off the Weierstrass curve", u)
+			}
+			u2, v2 := c.FromWeierstrassPoint(p)
+			if u2.Cmp(u) != 0 || v2.Cmp(c.V(u)) != 0 {
+				t.Fatalf("u = %d doesn't survive the round trip", u)
+			}
+		}
+	}
+}
+
+func TestMontgomeryIsOnCurve(t *testing.T) {
+	for _, c := range montgomeryCurves() {
+		w := c.ToWeierstrass()
+		for i := 0; i < trials; i++ {
+			u, err := c.RandomTwistPoint()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.V(u) != nil {
+				t.Fatalf("u = %d should be on the twist", u)
+			}
+
+			// The Weierstrass x for u shouldn't have a y either
+			x := c.ToWeierstrassPoint(u, new(big.Int)).X
+			if new(big.Int).ModSqrt(w.rhs(x), w.P) != nil {
+				t.Fatalf("u = %d is on the twist, but x = %d is on the curve", u, x)
+			}
+		}
+	}
+}
+
+func TestXDouble(t *testing.T) {
+	for _, c := range montgomeryCurves() {
+		w := c.ToWeierstrass()
+		for i := 0; i < trials; i++ {
+			u, p := randomMontgomeryPoint(t, c)
+			got := c.Affine(c.XDouble(NewXPoint(u)))
+			if expected := uOf(c, w.Double(p)); got.Cmp(expected) != 0 {
+				t.Fatalf("x(2P) = %d, expected %d", got, expected)
+			}
+		}
+	}
+}
+
+func TestXAdd(t *testing.T) {
+	for _, c := range montgomeryCurves() {
+		w := c.ToWeierstrass()
+		for i := 0; i < trials; i++ {
+			u1, p := randomMontgomeryPoint(t, c)
+			u2, q := randomMontgomeryPoint(t, c)
+			diff := NewXPoint(uOf(c, w.Add(p, w.Neg(q))))
+
+			got := c.Affine(c.XAdd(NewXPoint(u1), NewXPoint(u2), diff))
+			if expected := uOf(c, w.Add(p, q)); got.Cmp(expected) != 0 {
+				t.Fatalf("x(P+Q) = %d, expected %d", got, expected)
+			}
+		}
+	}
+}
+
+func TestLadder(t *testing.T) {
+	for _, c := range montgomeryCurves() {
+		w := c.ToWeierstrass()
+		for i := 0; i < trials; i++ {
+			u, p := randomMontgomeryPoint(t, c)
+			k, err := rand.Int(rand.Reader, c.P)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := c.Ladder(u, k)
+			if expected := uOf(c, w.ScalarMult(p, k)); got.Cmp(expected) != 0 {
+				t.Fatalf("Ladder(%d, %d) = %d, expected %d", u, k, got, expected)
+			}
+		}
+	}
+}
+
+func TestLadderSmallScalars(t *testing.T) {
+	c := montgomeryCurves()[0]
+	w := c.ToWeierstrass()
+	u, p := randomMontgomeryPoint(t, c)
+
+	multiple := Infinity()
+	for k := int64(0); k < 16; k++ {
+		got := c.Ladder(u, big.NewInt(k))
+		if expected := uOf(c, multiple); got.Cmp(expected) != 0 {
+			t.Fatalf("Ladder(u, %d) = %d, expected %d", k, got, expected)
+		}
+		multiple = w.Add(multiple, p)
+	}
+}
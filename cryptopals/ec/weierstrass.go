@@ -0,0 +1,186 @@
+// Package ec implements elliptic curve arithmetic over prime fields with
+// math/big, for the attacks in Set 8.
+//
+// None of it is constant time, and nothing checks that the points it's given
+// are actually on the curve. That's the point.
+package ec
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+)
+
+var (
+	big1 = big.NewInt(1)
+	big2 = big.NewInt(2)
+	big3 = big.NewInt(3)
+)
+
+// A point on an elliptic curve in affine coordinates. The point at infinity,
+// the identity of the group, has nil coordinates.
+type Point struct {
+	X, Y *big.Int
+}
+
+// The point at infinity
+func Infinity() *Point {
+	return &Point{}
+}
+
+func NewPoint(x, y *big.Int) *Point {
+	return &Point{new(big.Int).Set(x), new(big.Int).Set(y)}
+}
+
+func (p *Point) IsInfinity() bool {
+	return p == nil || p.X == nil || p.Y == nil
+}
+
+func (p *Point) Equal(q *Point) bool {
+	if p.IsInfinity() || q.IsInfinity() {
+		return p.IsInfinity() && q.IsInfinity()
+	}
+	return p.X.Cmp(q.X) == 0 && p.Y.Cmp(q.Y) == 0
+}
+
+// A curve in short Weierstrass form over GF(P):
+//
+//	y^2 = x^3 + a*x + b
+type Curve struct {
+	A, B, P *big.Int
+}
+
+// Returns the curve with the same a and p, but a different b. The group law
+// never uses b, so points on this curve can be fed to the original's methods.
+func (c *Curve) WithB(b *big.Int) *Curve {
+	return &Curve{c.A, b, c.P}
+}
+
+// x^3 + a*x + b mod p
+func (c *Curve) rhs(x *big.Int) *big.Int {
+	y2 := new(big.Int).Mul(x, x)
+	y2.Add(y2, c.A)
+	y2.Mul(y2, x)
+	y2.Add(y2, c.B)
+	return y2.Mod(y2, c.P)
+}
+
+func (c *Curve) IsOnCurve(p *Point) bool {
+	if p.IsInfinity() {
+		return true
+	}
+	y2 := new(big.Int).Mul(p.Y, p.Y)
+	y2.Mod(y2, c.P)
+	return y2.Cmp(c.rhs(p.X)) == 0
+}
+
+// -(x, y) = (x, -y)
+func (c *Curve) Neg(p *Point) *Point {
+	if p.IsInfinity() {
+		return Infinity()
+	}
+	y := new(big.Int).Neg(p.Y)
+	return &Point{new(big.Int).Set(p.X), y.Mod(y, c.P)}
+}
+
+// Adds two points with the chord and tangent rule
+func (c *Curve) Add(p1, p2 *Point) *Point {
+	if p1.IsInfinity() {
+		return c.copy(p2)
+	}
+	if p2.IsInfinity() {
+		return c.copy(p1)
+	}
+	if p1.Equal(c.Neg(p2)) {
+		return Infinity()
+	}
+
+	var m *big.Int
+	if p1.Equal(p2) {
+		// m = (3*x1^2 + a) / (2*y1)
+		m = new(big.Int).Mul(p1.X, p1.X)
+		m.Mul(m, big3)
+		m.Add(m, c.A)
+		d := new(big.Int).Mul(p1.Y, big2)
+		m.Mul(m, d.ModInverse(d, c.P))
+	} else {
+		// m = (y2 - y1) / (x2 - x1)
+		m = new(big.Int).Sub(p2.Y, p1.Y)
+		d := new(big.Int).Sub(p2.X, p1.X)
+		d.Mod(d, c.P)
+		m.Mul(m, d.ModInverse(d, c.P))
+	}
+	m.Mod(m, c.P)
+
+	// x3 = m^2 - x1 - x2
+	x3 := new(big.Int).Mul(m, m)
+	x3.Sub(x3, p1.X)
+	x3.Sub(x3, p2.X)
+	x3.Mod(x3, c.P)
+
+	// y3 = m*(x1 - x3) - y1
+	y3 := new(big.Int).Sub(p1.X, x3)
+	y3.Mul(y3, m)
+	y3.Sub(y3, p1.Y)
+	y3.Mod(y3, c.P)
+
+	return &Point{x3, y3}
+}
+
+func (c *Curve) Double(p *Point) *Point {
+	return c.Add(p, p)
+}
+
+// Computes k*p with double-and-add. k must not be negative.
+func (c *Curve) ScalarMult(p *Point, k *big.Int) *Point {
+	result := Infinity()
+	for i := k.BitLen() - 1; i >= 0; i-- {
+		result = c.Double(result)
+		if k.Bit(i) == 1 {
+			result = c.Add(result, p)
+		}
+	}
+	return result
+}
+
+// Finds a random point on the curve by picking random x coordinates until
+// x^3 + a*x + b is a square
+func (c *Curve) RandomPoint() (*Point, error) {
+	for {
+		x, err := rand.Int(rand.Reader, c.P)
+		if err != nil {
+			return nil, err
+		}
+		if y := new(big.Int).ModSqrt(c.rhs(x), c.P); y != nil {
+			return &Point{x, y}, nil
+		}
+	}
+}
+
+// Finds a random point of prime order r on a curve with `order` points. r
+// must divide the order of the curve, and shouldn't divide it more than once:
+// if the points of order r don't form a cyclic group, multiplying by order/r
+// always gives the identity and this never returns.
+func (c *Curve) PointOfOrder(r, order *big.Int) (*Point, error) {
+	cofactor, m := new(big.Int).DivMod(order, r, new(big.Int))
+	if m.Sign() != 0 {
+		return nil, errors.New("r does not divide the order of the curve")
+	}
+
+	for {
+		p, err := c.RandomPoint()
+		if err != nil {
+			return nil, err
+		}
+		if h := c.ScalarMult(p, cofactor); !h.IsInfinity() {
+			return h, nil
+		}
+	}
+}
+
+func (c *Curve) copy(p *Point) *Point {
+	if p.IsInfinity() {
+		return Infinity()
+	}
+	return NewPoint(p.X, p.Y)
+}
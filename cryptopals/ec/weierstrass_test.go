@@ -0,0 +1,103 @@
+package ec
+
+import (
+	"math/big"
+	"testing"
+)
+
+// y^2 = x^3 + 2x + 2 over GF(17), where (5, 1) generates all 19 points
+func toyGroup() *Group {
+	return &Group{
+		Curve: &Curve{big.NewInt(2), big.NewInt(2), big.NewInt(17)},
+		G:     &Point{big.NewInt(5), big.NewInt(1)},
+		N:     big.NewInt(19),
+	}
+}
+
+func point(x, y int64) *Point {
+	return &Point{big.NewInt(x), big.NewInt(y)}
+}
+
+func TestDouble(t *testing.T) {
+	g := toyGroup()
+	if p := g.Curve.Double(g.G); !p.Equal(point(6, 3)) {
+		t.Errorf("Expected 2G = (6, 3), got (%d, %d)", p.X, p.Y)
+	}
+}
+
+func TestAdd(t *testing.T) {
+	g := toyGroup()
+	c := g.Curve
+
+	if p := c.Add(g.G, point(6, 3)); !p.Equal(point(10, 6)) {
+		t.Errorf("Expected 3G = (10, 6), got (%d, %d)", p.X, p.Y)
+	}
+	if p := c.Add(g.G, c.Neg(g.G)); !p.IsInfinity() {
+		t.Errorf("Expected G - G to be the point at infinity")
+	}
+	if p := c.Add(Infinity(), g.G); !p.Equal(g.G) {
+		t.Errorf("Expected O + G = G")
+	}
+}
+
+func TestScalarMult(t *testing.T) {
+	g := toyGroup()
+	c := g.Curve
+
+	sum := Infinity()
+	for k := int64(0); k < 19; k++ {
+		p := c.ScalarMult(g.G, big.NewInt(k))
+		if !p.Equal(sum) {
+			t.Fatalf("%dG doesn't match repeated addition", k)
+		}
+		if !c.IsOnCurve(p) {
+			t.Fatalf("%dG isn't on the curve", k)
+		}
+		sum = c.Add(sum, g.G)
+	}
+
+	if p := c.ScalarMult(g.G, g.N); !p.IsInfinity() {
+		t.Errorf("Expected NG to be the point at infinity")
+	}
+}
+
+func TestIsOnCurve(t *testing.T) {
+	c := toyGroup().Curve
+	if c.IsOnCurve(point(5, 2)) {
+		t.Errorf("(5, 2) shouldn't be on the curve")
+	}
+	if !c.IsOnCurve(Infinity()) {
+		t.Errorf("The point at infinity should be on the curve")
+	}
+}
+
+func TestPointOfOrder(t *testing.T) {
+	c := toyGroup().Curve
+	h, err := c.PointOfOrder(big.NewInt(19), big.NewInt(19))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !c.IsOnCurve(h) || !c.ScalarMult(h, big.NewInt(19)).IsInfinity() {
+		t.Errorf("(%d, %d) doesn't have order 19", h.X, h.Y)
+	}
+
+	if _, err := c.PointOfOrder(big.NewInt(7), big.NewInt(19)); err == nil {
+		t.Error("Expected an error when r doesn't divide the order")
+	}
+}
+
+func TestECDH(t *testing.T) {
+	g := toyGroup()
+	alice, err := GenerateKey(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := GenerateKey(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !alice.SharedSecret(bob.Public).Equal(bob.SharedSecret(alice.Public)) {
+		t.Error("Alice and Bob derived different shared secrets")
+	}
+}
@@ -0,0 +1,155 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/gf128"
+)
+
+// Galois/Counter Mode, built on our CTR keystream and GHASH
+
+const (
+	gcmNonceSize = 12
+	gcmTagSize   = 16
+)
+
+// The AEADs from NewGCM implement this too, so attacks which recover H can
+// check their work
+type GCMAuthKeyer interface {
+	AuthKey() gf128.Element
+}
+
+var _ GCMAuthKeyer = (*gcm)(nil)
+
+type gcm struct {
+	b cipher.Block
+	// The authentication key, H = E(K, 0^128)
+	h       gf128.Element
+	tagSize int
+}
+
+// Wraps a 128-bit block cipher in GCM with a 96-bit nonce and a full 128-bit
+// tag. The result implements cipher.AEAD.
+func NewGCM(b cipher.Block) (cipher.AEAD, error) {
+	return NewGCMWithTagSize(b, gcmTagSize)
+}
+
+// Like NewGCM, but truncates the tag to tagSize bytes. Unlike crypto/cipher,
+// we allow tags as short as a single byte, which is a terrible idea.
+func NewGCMWithTagSize(b cipher.Block, tagSize int) (cipher.AEAD, error) {
+	if b.BlockSize() != gf128.Size {
+		return nil, errors.New("GCM requires a 128-bit block cipher")
+	}
+	if tagSize < 1 || tagSize > gcmTagSize {
+		return nil, errors.New("Invalid GCM tag size")
+	}
+	h := make([]byte, gf128.Size)
+	b.Encrypt(h, h)
+	return &gcm{b: b, h: gf128.FromBytes(h), tagSize: tagSize}, nil
+}
+
+func (g *gcm) NonceSize() int { return gcmNonceSize }
+func (g *gcm) Overhead() int  { return g.tagSize }
+
+// Returns the authentication key H
+func (g *gcm) AuthKey() gf128.Element { return g.h }
+
+// Computes GHASH over the additional data and ciphertext:
+//
+//	GHASH_H(A, C) = sum(b_i * H^(n-i+1))
+//
+// where b_1..b_n are the zero padded blocks of A, then C, then a block with
+// the bit lengths of A and C.
+func GHASH(h gf128.Element, ad, ciphertext []byte) gf128.Element {
+	var y gf128.Element
+	for _, block := range GHASHBlocks(ad, ciphertext) {
+		y = y.Add(block).Mul(h)
+	}
+	return y
+}
+
+// Splits the additional data and ciphertext into the blocks GHASH consumes
+func GHASHBlocks(ad, ciphertext []byte) []gf128.Element {
+	var blocks []gf128.Element
+	for _, data := range [][]byte{ad, ciphertext} {
+		for i := 0; i < len(data); i += gf128.Size {
+			end := i + gf128.Size
+			if end > len(data) {
+				end = len(data)
+			}
+			blocks = append(blocks, gf128.FromBytes(data[i:end]))
+		}
+	}
+
+	lengths := make([]byte, gf128.Size)
+	binary.BigEndian.PutUint64(lengths[:8], uint64(len(ad))*8)
+	binary.BigEndian.PutUint64(lengths[8:], uint64(len(ciphertext))*8)
+	return append(blocks, gf128.FromBytes(lengths))
+}
+
+// A CTR keystream starting from J0 = nonce || BE32(1). The first block masks
+// the tag, and the message is encrypted with the rest.
+func (g *gcm) ctr(nonce []byte) *ctr {
+	j0 := make([]byte, gf128.Size)
+	copy(j0, nonce)
+	j0[gf128.Size-1] = 1
+
+	x, err := NewCTRWithLayout(g.b, j0, CounterNonce96)
+	if err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// The keystream for the message, from inc32(J0)
+func (g *gcm) messageCTR(nonce []byte) *ctr {
+	x := g.ctr(nonce)
+	if err := x.Seek(gf128.Size); err != nil {
+		panic(err)
+	}
+	return x
+}
+
+// GHASH(A, C) + E(K, J0), truncated to the tag size
+func (g *gcm) tag(nonce, ad, ciphertext []byte) []byte {
+	s := g.ctr(nonce).KeystreamBytes(0, gf128.Size)
+	return GHASH(g.h, ad, ciphertext).Add(gf128.FromBytes(s)).Bytes()[:g.tagSize]
+}
+
+// Encrypts and authenticates plaintext, authenticates the additional data and
+// appends the result to dst
+func (g *gcm) Seal(dst, nonce, plaintext, ad []byte) []byte {
+	if len(nonce) != gcmNonceSize {
+		panic("cryptopals: incorrect nonce length given to GCM")
+	}
+
+	ciphertext := make([]byte, len(plaintext))
+	g.messageCTR(nonce).XORKeyStream(ciphertext, plaintext)
+
+	dst = append(dst, ciphertext...)
+	return append(dst, g.tag(nonce, ad, ciphertext)...)
+}
+
+// Authenticates and decrypts ciphertext and the additional data, and appends
+// the plaintext to dst
+func (g *gcm) Open(dst, nonce, ciphertext, ad []byte) ([]byte, error) {
+	if len(nonce) != gcmNonceSize {
+		panic("cryptopals: incorrect nonce length given to GCM")
+	}
+	if len(ciphertext) < g.tagSize {
+		return nil, errors.New("Ciphertext is too short")
+	}
+
+	tag := ciphertext[len(ciphertext)-g.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-g.tagSize]
+	if subtle.ConstantTimeCompare(tag, g.tag(nonce, ad, ciphertext)) != 1 {
+		return nil, errors.New("Message authentication failed")
+	}
+
+	plaintext := make([]byte, len(ciphertext))
+	g.messageCTR(nonce).XORKeyStream(plaintext, ciphertext)
+	return append(dst, plaintext...), nil
+}
@@ -0,0 +1,121 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+)
+
+func randomBytes(t *testing.T, n int) []byte {
+	b, err := GenerateRandomBytes(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestGCMTestVector(t *testing.T) {
+	// Test Case 4 from the GCM specification
+	key, _ := hex.DecodeString("feffe9928665731c6d6a8f9467308308")
+	nonce, _ := hex.DecodeString("cafebabefacedbaddecaf888")
+	ad, _ := hex.DecodeString("feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext, _ := hex.DecodeString("d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a721c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+	expected, _ := hex.DecodeString("42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091" +
+		"5bc94fbc3221a5db94fae95ae7121a47")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealed := g.Seal(nil, nonce, plaintext, ad)
+	if !bytes.Equal(sealed, expected) {
+		t.Errorf("Expected %x, got %x", expected, sealed)
+	}
+
+	opened, err := g.Open(nil, nonce, sealed, ad)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(opened, plaintext) {
+		t.Errorf("Expected %x, got %x", plaintext, opened)
+	}
+}
+
+func TestGCMMatchesStdlib(t *testing.T) {
+	key, nonce := randomBytes(t, 16), randomBytes(t, 12)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdlib, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, size := range []int{0, 1, 15, 16, 17, 100} {
+		plaintext, ad := randomBytes(t, size), randomBytes(t, size/2)
+		expected := stdlib.Seal(nil, nonce, plaintext, ad)
+		if sealed := g.Seal(nil, nonce, plaintext, ad); !bytes.Equal(sealed, expected) {
+			t.Errorf("Size %d: expected %x, got %x", size, expected, sealed)
+		}
+	}
+}
+
+func TestGCMOpenRejectsForgery(t *testing.T) {
+	block, err := aes.NewCipher(randomBytes(t, 16))
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	nonce := randomBytes(t, 12)
+	sealed := g.Seal(nil, nonce, []byte("attack at dawn"), nil)
+	sealed[0] ^= 1
+	if _, err := g.Open(nil, nonce, sealed, nil); err == nil {
+		t.Error("Opened a modified ciphertext")
+	}
+}
+
+func TestGCMTruncatedTagMatchesStdlib(t *testing.T) {
+	key, nonce := randomBytes(t, 16), randomBytes(t, 12)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := NewGCMWithTagSize(block, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdlib, err := cipher.NewGCMWithTagSize(block, 12)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, ad := randomBytes(t, 40), randomBytes(t, 7)
+	expected := stdlib.Seal(nil, nonce, plaintext, ad)
+	sealed := g.Seal(nil, nonce, plaintext, ad)
+	if !bytes.Equal(sealed, expected) {
+		t.Errorf("Expected %x, got %x", expected, sealed)
+	}
+	if _, err := g.Open(nil, nonce, sealed, ad); err != nil {
+		t.Error(err)
+	}
+
+	if _, err := NewGCMWithTagSize(block, 17); err == nil {
+		t.Error("Accepted a tag longer than a block")
+	}
+}
@@ -0,0 +1,120 @@
+// Package gf128 implements arithmetic in GF(2^128), the field GCM uses for
+// GHASH, and polynomials over it.
+//
+// Elements are polynomials in x modulo x^128 + x^7 + x^2 + x + 1, with the
+// same bit order as GCM: the most significant bit of the first byte of a
+// block is the coefficient of x^0, and the least significant bit of the last
+// byte is the coefficient of x^127.
+package gf128
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+)
+
+// The size of an element in bytes
+const Size = 16
+
+// The first and second halves of a 16 byte block, as big-endian integers
+type Element [2]uint64
+
+// The multiplicative identity, x^0
+var One = Element{1 << 63, 0}
+
+// R = x^7 + x^2 + x + 1, which x^128 reduces to
+const r = 0xe1 << 56
+
+// Converts a block of up to 16 bytes to an element. Shorter blocks are padded
+// with zeros, like GHASH does.
+func FromBytes(b []byte) Element {
+	var block [Size]byte
+	copy(block[:], b)
+	return Element{binary.BigEndian.Uint64(block[:8]), binary.BigEndian.Uint64(block[8:])}
+}
+
+func (a Element) Bytes() []byte {
+	b := make([]byte, Size)
+	binary.BigEndian.PutUint64(b[:8], a[0])
+	binary.BigEndian.PutUint64(b[8:], a[1])
+	return b
+}
+
+// A random element
+func Random() (Element, error) {
+	b := make([]byte, Size)
+	if _, err := rand.Read(b); err != nil {
+		return Element{}, err
+	}
+	return FromBytes(b), nil
+}
+
+func (a Element) IsZero() bool {
+	return a[0] == 0 && a[1] == 0
+}
+
+// Addition and subtraction are both XOR
+func (a Element) Add(b Element) Element {
+	return Element{a[0] ^ b[0], a[1] ^ b[1]}
+}
+
+// Multiplies two elements with Algorithm 1 from NIST SP 800-38D. Each bit of
+// a, starting from x^0, adds the matching power of x times b to the result.
+// Multiplying by x is a right shift in GCM's bit order.
+func (a Element) Mul(b Element) Element {
+	var z Element
+	v := b
+	for i := uint(0); i < 128; i++ {
+		var bit uint64
+		if i < 64 {
+			bit = (a[0] >> (63 - i)) & 1
+		} else {
+			bit = (a[1] >> (127 - i)) & 1
+		}
+		if bit == 1 {
+			z[0] ^= v[0]
+			z[1] ^= v[1]
+		}
+
+		// v = v * x
+		carry := v[1] & 1
+		v[1] = (v[1] >> 1) | (v[0] << 63)
+		v[0] >>= 1
+		if carry == 1 {
+			v[0] ^= r
+		}
+	}
+	return z
+}
+
+func (a Element) Square() Element {
+	return a.Mul(a)
+}
+
+// Computes a^n with square-and-multiply
+func (a Element) Exp(n *big.Int) Element {
+	result := One
+	for i := n.BitLen() - 1; i >= 0; i-- {
+		result = result.Square()
+		if n.Bit(i) == 1 {
+			result = result.Mul(a)
+		}
+	}
+	return result
+}
+
+// a^-1 = a^(2^128 - 2), since the multiplicative group has order 2^128 - 1
+func (a Element) Inverse() Element {
+	n := new(big.Int).Lsh(big.NewInt(1), 128)
+	n.Sub(n, big.NewInt(2))
+	return a.Exp(n)
+}
+
+// Every element has exactly one square root in a field of characteristic 2:
+// sqrt(a) = a^(2^127)
+func (a Element) Sqrt() Element {
+	for i := 0; i < 127; i++ {
+		a = a.Square()
+	}
+	return a
+}
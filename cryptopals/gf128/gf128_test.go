@@ -0,0 +1,51 @@
+package gf128
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func element(t *testing.T, s string) Element {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return FromBytes(b)
+}
+
+func TestMul(t *testing.T) {
+	// H and X_1 from Test Case 2 of the GCM specification, where
+	// GHASH(H, {}, C) = X_1 * H
+	h := element(t, "66e94bd4ef8a2c3b884cfa59ca342b2e")
+	c := element(t, "0388dace60b6a392f328c2b971b2fe78")
+	lengths := element(t, "00000000000000000000000000000080")
+	expected := element(t, "f38cbb1ad69223dcc3457ae5b6b0f885")
+
+	if ghash := c.Mul(h).Add(lengths).Mul(h); ghash != expected {
+		t.Errorf("Expected %x, got %x", expected.Bytes(), ghash.Bytes())
+	}
+}
+
+func TestFieldAxioms(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		a, _ := Random()
+		b, _ := Random()
+		c, _ := Random()
+
+		if a.Mul(b) != b.Mul(a) {
+			t.Error("Multiplication isn't commutative")
+		}
+		if a.Mul(b.Add(c)) != a.Mul(b).Add(a.Mul(c)) {
+			t.Error("Multiplication doesn't distribute over addition")
+		}
+		if a.Mul(One) != a {
+			t.Error("One isn't the identity")
+		}
+		if a.Mul(a.Inverse()) != One {
+			t.Errorf("%x has no inverse", a.Bytes())
+		}
+		if a.Sqrt().Square() != a {
+			t.Errorf("Bad square root of %x", a.Bytes())
+		}
+	}
+}
@@ -0,0 +1,342 @@
+package gf128
+
+import (
+	"errors"
+)
+
+// A polynomial over GF(2^128), with the constant term first. The zero
+// polynomial has no coefficients, and the leading coefficient of any other
+// polynomial is nonzero.
+type Poly []Element
+
+// A factor of a polynomial. For square-free factorization, N is the
+// multiplicity of the factor. For distinct-degree factorization, N is the
+// degree of each of the irreducible factors which multiply to Poly.
+type Factor struct {
+	Poly Poly
+	N    int
+}
+
+// Builds a polynomial from its coefficients, constant term first, dropping
+// any leading zeros
+func NewPoly(coefficients ...Element) Poly {
+	p := append(Poly{}, coefficients...)
+	return p.trim()
+}
+
+// x, the polynomial
+var X = Poly{{}, One}
+
+func (p Poly) trim() Poly {
+	for len(p) > 0 && p[len(p)-1].IsZero() {
+		p = p[:len(p)-1]
+	}
+	return p
+}
+
+// The degree of the polynomial, with -1 for the zero polynomial
+func (p Poly) Degree() int {
+	return len(p) - 1
+}
+
+func (p Poly) IsZero() bool {
+	return len(p) == 0
+}
+
+// Whether p is the constant 1
+func (p Poly) IsOne() bool {
+	return len(p) == 1 && p[0] == One
+}
+
+func (p Poly) Equal(q Poly) bool {
+	if len(p) != len(q) {
+		return false
+	}
+	for i := range p {
+		if p[i] != q[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Evaluates p(x) with Horner's method
+func (p Poly) Eval(x Element) Element {
+	var y Element
+	for i := len(p) - 1; i >= 0; i-- {
+		y = y.Mul(x).Add(p[i])
+	}
+	return y
+}
+
+func (p Poly) Add(q Poly) Poly {
+	if len(p) < len(q) {
+		p, q = q, p
+	}
+	sum := append(Poly{}, p...)
+	for i := range q {
+		sum[i] = sum[i].Add(q[i])
+	}
+	return sum.trim()
+}
+
+func (p Poly) Mul(q Poly) Poly {
+	if p.IsZero() || q.IsZero() {
+		return Poly{}
+	}
+	product := make(Poly, len(p)+len(q)-1)
+	for i := range p {
+		for j := range q {
+			product[i+j] = product[i+j].Add(p[i].Mul(q[j]))
+		}
+	}
+	return product.trim()
+}
+
+// Multiplies every coefficient by c
+func (p Poly) Scale(c Element) Poly {
+	scaled := make(Poly, len(p))
+	for i := range p {
+		scaled[i] = p[i].Mul(c)
+	}
+	return scaled.trim()
+}
+
+// Scales p so its leading coefficient is 1
+func (p Poly) Monic() Poly {
+	if p.IsZero() {
+		return p
+	}
+	return p.Scale(p[len(p)-1].Inverse())
+}
+
+// Divides p by q with long division. q must not be zero.
+func (p Poly) DivMod(q Poly) (quotient, remainder Poly) {
+	if q.IsZero() {
+		panic("gf128: division by the zero polynomial")
+	}
+
+	remainder = append(Poly{}, p...)
+	if len(p) < len(q) {
+		return Poly{}, remainder
+	}
+
+	quotient = make(Poly, len(p)-len(q)+1)
+	inv := q[len(q)-1].Inverse()
+	for remainder.Degree() >= q.Degree() {
+		shift := remainder.Degree() - q.Degree()
+		c := remainder[len(remainder)-1].Mul(inv)
+		quotient[shift] = c
+		for i := range q {
+			remainder[shift+i] = remainder[shift+i].Add(q[i].Mul(c))
+		}
+		remainder = remainder.trim()
+	}
+	return quotient.trim(), remainder
+}
+
+func (p Poly) Div(q Poly) Poly {
+	quotient, _ := p.DivMod(q)
+	return quotient
+}
+
+func (p Poly) Mod(q Poly) Poly {
+	_, remainder := p.DivMod(q)
+	return remainder
+}
+
+// The monic greatest common divisor of p and q
+func GCD(p, q Poly) Poly {
+	for !q.IsZero() {
+		p, q = q, p.Mod(q)
+	}
+	return p.Monic()
+}
+
+// The formal derivative. In characteristic 2, the terms with even powers of
+// x drop out and the odd ones lose their multiplier.
+func (p Poly) Derivative() Poly {
+	if len(p) < 2 {
+		return Poly{}
+	}
+	d := make(Poly, len(p)-1)
+	for i := 1; i < len(p); i += 2 {
+		d[i-1] = p[i]
+	}
+	return d.trim()
+}
+
+// The square root of a polynomial whose derivative is zero, which makes it a
+// perfect square in characteristic 2:
+//
+//	sqrt(sum(a_i * x^(2i))) = sum(sqrt(a_i) * x^i)
+func (p Poly) Sqrt() Poly {
+	root := make(Poly, (len(p)+1)/2)
+	for i := range root {
+		root[i] = p[2*i].Sqrt()
+	}
+	return root.trim()
+}
+
+// Computes p^(2^k) mod f by squaring k times
+func (p Poly) SquareMod(k int, f Poly) Poly {
+	p = p.Mod(f)
+	for i := 0; i < k; i++ {
+		p = p.Mul(p).Mod(f)
+	}
+	return p
+}
+
+// Computes the square-free factorization of a monic polynomial p: square-free
+// polynomials f_i such that p = prod(f_i^N_i).
+func (p Poly) SquareFree() []Factor {
+	var factors []Factor
+
+	c := GCD(p, p.Derivative())
+	w := p.Div(c)
+
+	// Every factor in w has multiplicity i in what's left of p. Each pass
+	// peels off the factors which don't appear in c again.
+	for i := 1; !w.IsOne(); i++ {
+		y := GCD(w, c)
+		if factor := w.Div(y); !factor.IsOne() {
+			factors = append(factors, Factor{factor, i})
+		}
+		w = y
+		c = c.Div(y)
+	}
+
+	// What's left is a perfect square, whose factors have multiplicities
+	// which are multiples of 2
+	if !c.IsOne() {
+		for _, f := range c.Sqrt().SquareFree() {
+			factors = append(factors, Factor{f.Poly, f.N * 2})
+		}
+	}
+
+	return factors
+}
+
+// Splits a monic, square-free polynomial into the products of its
+// irreducible factors of each degree.
+//
+// The irreducible polynomials of degree i are exactly the factors of
+// x^(q^i) - x which aren't factors for any smaller i, where q = 2^128.
+func (p Poly) DistinctDegree() []Factor {
+	var factors []Factor
+
+	// h = x^(q^i) mod p
+	h := X
+	for i := 1; p.Degree() >= 2*i; i++ {
+		h = h.SquareMod(128, p)
+		if g := GCD(p, h.Add(X)); !g.IsOne() {
+			factors = append(factors, Factor{g, i})
+			p = p.Div(g)
+			h = h.Mod(p)
+		}
+	}
+
+	if p.Degree() > 0 {
+		factors = append(factors, Factor{p.Monic(), p.Degree()})
+	}
+
+	return factors
+}
+
+// A random polynomial of degree less than n
+func randomPoly(n int) (Poly, error) {
+	p := make(Poly, n)
+	for i := range p {
+		e, err := Random()
+		if err != nil {
+			return nil, err
+		}
+		p[i] = e
+	}
+	return p.trim(), nil
+}
+
+// Splits a monic, square-free polynomial whose irreducible factors all have
+// degree d into those factors, with the Cantor-Zassenhaus algorithm.
+//
+// In characteristic 2 we split with the trace map instead of the usual
+// (q^d - 1)/2 power: for a random h, Tr(h) = h + h^2 + h^4 + ... +
+// h^(2^(128d - 1)) is either 0 or 1 mod each irreducible factor, so
+// gcd(p, Tr(h)) will usually be a proper factor.
+func (p Poly) EqualDegree(d int) ([]Poly, error) {
+	n := p.Degree()
+	if n%d != 0 {
+		return nil, errors.New("The degree of the polynomial isn't a multiple of d")
+	}
+	if n == d {
+		return []Poly{p}, nil
+	}
+
+	for {
+		h, err := randomPoly(n)
+		if err != nil {
+			return nil, err
+		}
+
+		trace := h.Mod(p)
+		term := trace
+		for i := 1; i < 128*d; i++ {
+			term = term.SquareMod(1, p)
+			trace = trace.Add(term)
+		}
+
+		g := GCD(p, trace)
+		if g.Degree() <= 0 || g.Degree() == n {
+			continue
+		}
+
+		left, err := g.EqualDegree(d)
+		if err != nil {
+			return nil, err
+		}
+		right, err := p.Div(g).EqualDegree(d)
+		if err != nil {
+			return nil, err
+		}
+		return append(left, right...), nil
+	}
+}
+
+// Factors p into monic irreducible polynomials, with the multiplicity of
+// each
+func (p Poly) Factor() ([]Factor, error) {
+	if p.Degree() < 1 {
+		return nil, nil
+	}
+
+	var factors []Factor
+	for _, sf := range p.Monic().SquareFree() {
+		for _, dd := range sf.Poly.DistinctDegree() {
+			irreducible, err := dd.Poly.EqualDegree(dd.N)
+			if err != nil {
+				return nil, err
+			}
+			for _, f := range irreducible {
+				factors = append(factors, Factor{f, sf.N})
+			}
+		}
+	}
+	return factors, nil
+}
+
+// Finds the distinct roots of p in GF(2^128). Each root a is a linear factor
+// x + a.
+func (p Poly) Roots() ([]Element, error) {
+	factors, err := p.Factor()
+	if err != nil {
+		return nil, err
+	}
+
+	var roots []Element
+	for _, f := range factors {
+		if f.Poly.Degree() == 1 {
+			roots = append(roots, f.Poly[0])
+		}
+	}
+	return roots, nil
+}
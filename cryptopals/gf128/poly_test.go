@@ -0,0 +1,102 @@
+package gf128
+
+import (
+	"sort"
+	"testing"
+)
+
+func random(t *testing.T) Element {
+	e, err := Random()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// x + a
+func linear(a Element) Poly {
+	return NewPoly(a, One)
+}
+
+func TestDivMod(t *testing.T) {
+	p := NewPoly(random(t), random(t), random(t), random(t), random(t))
+	q := NewPoly(random(t), random(t), random(t))
+
+	quotient, remainder := p.DivMod(q)
+	if remainder.Degree() >= q.Degree() {
+		t.Errorf("The remainder has degree %d", remainder.Degree())
+	}
+	if !quotient.Mul(q).Add(remainder).Equal(p) {
+		t.Error("p != q*quotient + remainder")
+	}
+}
+
+func TestSquareFree(t *testing.T) {
+	a, b, c := linear(random(t)), linear(random(t)), linear(random(t))
+	// a * b^2 * c^3
+	p := a.Mul(b).Mul(b).Mul(c).Mul(c).Mul(c)
+
+	// Factors with even multiplicity come out of the perfect square at the
+	// end, so the order isn't by multiplicity
+	factors := p.SquareFree()
+	if len(factors) != 3 {
+		t.Fatalf("Expected 3 factors, got %d", len(factors))
+	}
+	for _, f := range factors {
+		if f.N < 1 || f.N > 3 {
+			t.Fatalf("Unexpected multiplicity %d", f.N)
+		}
+		expected := []Poly{a, b, c}[f.N-1]
+		if !f.Poly.Equal(expected) {
+			t.Errorf("Expected %v^%d, got %v^%d", expected, f.N, f.Poly, f.N)
+		}
+	}
+}
+
+func TestSquareFreeOfSquare(t *testing.T) {
+	a := linear(random(t))
+	factors := a.Mul(a).SquareFree()
+	if len(factors) != 1 || factors[0].N != 2 || !factors[0].Poly.Equal(a) {
+		t.Errorf("Expected %v^2, got %v", a, factors)
+	}
+}
+
+func TestRoots(t *testing.T) {
+	var expected []Element
+	p := Poly{One}
+	for i := 0; i < 4; i++ {
+		root := random(t)
+		expected = append(expected, root)
+		p = p.Mul(linear(root))
+	}
+	// Throw in a quadratic with no roots: x^2 + x + c is irreducible whenever
+	// Tr(c) = 1, so keep trying until DistinctDegree says so.
+	for {
+		q := NewPoly(random(t), One, One)
+		if dd := q.DistinctDegree(); len(dd) == 1 && dd[0].N == 2 {
+			p = p.Mul(q)
+			break
+		}
+	}
+
+	roots, err := p.Scale(random(t)).Roots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != len(expected) {
+		t.Fatalf("Expected %d roots, got %d", len(expected), len(roots))
+	}
+
+	less := func(s []Element) func(i, j int) bool {
+		return func(i, j int) bool {
+			return s[i][0] < s[j][0] || (s[i][0] == s[j][0] && s[i][1] < s[j][1])
+		}
+	}
+	sort.Slice(roots, less(roots))
+	sort.Slice(expected, less(expected))
+	for i := range roots {
+		if roots[i] != expected[i] {
+			t.Errorf("Expected root %x, got %x", expected[i].Bytes(), roots[i].Bytes())
+		}
+	}
+}
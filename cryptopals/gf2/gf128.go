@@ -0,0 +1,48 @@
+package gf2
+
+import (
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/gf128"
+)
+
+// GF(2^128) is a vector space over GF(2), and multiplying by a constant or
+// squaring are both linear maps on it, so we can write them as 128x128
+// matrices. Bit i of the vector is the coefficient of x^i.
+
+// The coefficients of e as a vector
+func ElementVector(e gf128.Element) Vector {
+	return Vector{e[0], e[1]}
+}
+
+func VectorElement(v Vector) gf128.Element {
+	return gf128.Element{v[0], v[1]}
+}
+
+// x^i
+func basisElement(i int) gf128.Element {
+	v := NewVector(128)
+	v.SetBit(i, 1)
+	return VectorElement(v)
+}
+
+// Builds the matrix for a linear map on GF(2^128). Column i is the image of
+// x^i.
+func linearMap(f func(gf128.Element) gf128.Element) *Matrix {
+	m := NewMatrix(128, 128)
+	for i := 0; i < 128; i++ {
+		image := ElementVector(f(basisElement(i)))
+		for j := 0; j < 128; j++ {
+			m.Set(j, i, image.Bit(j))
+		}
+	}
+	return m
+}
+
+// The matrix M_c with M_c * y = c * y
+func MulMatrix(c gf128.Element) *Matrix {
+	return linearMap(c.Mul)
+}
+
+// The matrix M_s with M_s * y = y^2
+func SquareMatrix() *Matrix {
+	return linearMap(gf128.Element.Square)
+}
@@ -0,0 +1,256 @@
+// Package gf2 implements vectors and matrices over GF(2), packed into 64-bit
+// words so row operations are cheap.
+package gf2
+
+import (
+	"math/bits"
+)
+
+// A vector over GF(2). Bit i is stored in word i/64, starting from the most
+// significant bit, which matches the bit order of a GF(2^128) element.
+type Vector []uint64
+
+// A zero vector with room for n bits
+func NewVector(n int) Vector {
+	return make(Vector, (n+63)/64)
+}
+
+func (v Vector) Bit(i int) uint {
+	return uint(v[i/64]>>(63-uint(i%64))) & 1
+}
+
+func (v Vector) SetBit(i int, b uint) {
+	mask := uint64(1) << (63 - uint(i%64))
+	if b&1 == 1 {
+		v[i/64] |= mask
+	} else {
+		v[i/64] &^= mask
+	}
+}
+
+func (v Vector) FlipBit(i int) {
+	v[i/64] ^= uint64(1) << (63 - uint(i%64))
+}
+
+// Adds w to v in place
+func (v Vector) Xor(w Vector) {
+	for i := range v {
+		v[i] ^= w[i]
+	}
+}
+
+// The dot product of v and w
+func (v Vector) Dot(w Vector) uint {
+	var sum int
+	for i := range v {
+		sum += bits.OnesCount64(v[i] & w[i])
+	}
+	return uint(sum & 1)
+}
+
+func (v Vector) IsZero() bool {
+	for _, word := range v {
+		if word != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (v Vector) Copy() Vector {
+	return append(Vector{}, v...)
+}
+
+// A Rows x Cols matrix over GF(2), stored by row
+type Matrix struct {
+	Rows, Cols int
+	rows       []Vector
+}
+
+// A zero matrix
+func NewMatrix(rows, cols int) *Matrix {
+	m := &Matrix{Rows: rows, Cols: cols, rows: make([]Vector, rows)}
+	for i := range m.rows {
+		m.rows[i] = NewVector(cols)
+	}
+	return m
+}
+
+func Identity(n int) *Matrix {
+	m := NewMatrix(n, n)
+	for i := 0; i < n; i++ {
+		m.Set(i, i, 1)
+	}
+	return m
+}
+
+func (m *Matrix) Get(i, j int) uint {
+	return m.rows[i].Bit(j)
+}
+
+func (m *Matrix) Set(i, j int, b uint) {
+	m.rows[i].SetBit(j, b)
+}
+
+// Row i of the matrix. It shares storage with m.
+func (m *Matrix) Row(i int) Vector {
+	return m.rows[i]
+}
+
+func (m *Matrix) Column(j int) Vector {
+	v := NewVector(m.Rows)
+	for i := 0; i < m.Rows; i++ {
+		v.SetBit(i, m.Get(i, j))
+	}
+	return v
+}
+
+func (m *Matrix) Copy() *Matrix {
+	c := &Matrix{Rows: m.Rows, Cols: m.Cols, rows: make([]Vector, m.Rows)}
+	for i := range m.rows {
+		c.rows[i] = m.rows[i].Copy()
+	}
+	return c
+}
+
+func (m *Matrix) Equal(n *Matrix) bool {
+	if m.Rows != n.Rows || m.Cols != n.Cols {
+		return false
+	}
+	for i := range m.rows {
+		for w := range m.rows[i] {
+			if m.rows[i][w] != n.rows[i][w] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Appends a row with Cols bits to the bottom of the matrix
+func (m *Matrix) AppendRow(v Vector) {
+	m.rows = append(m.rows, v.Copy())
+	m.Rows++
+}
+
+func (m *Matrix) Transpose() *Matrix {
+	t := NewMatrix(m.Cols, m.Rows)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			if m.Get(i, j) == 1 {
+				t.Set(j, i, 1)
+			}
+		}
+	}
+	return t
+}
+
+func (m *Matrix) Add(n *Matrix) *Matrix {
+	if m.Rows != n.Rows || m.Cols != n.Cols {
+		panic("gf2: matrix dimensions don't match")
+	}
+	sum := m.Copy()
+	for i := range sum.rows {
+		sum.rows[i].Xor(n.rows[i])
+	}
+	return sum
+}
+
+// Multiplies m by n. Each row of the product is the sum of the rows of n
+// picked out by the bits in the same row of m.
+func (m *Matrix) Mul(n *Matrix) *Matrix {
+	if m.Cols != n.Rows {
+		panic("gf2: matrix dimensions don't match")
+	}
+	product := NewMatrix(m.Rows, n.Cols)
+	for i := 0; i < m.Rows; i++ {
+		for j := 0; j < m.Cols; j++ {
+			if m.Get(i, j) == 1 {
+				product.rows[i].Xor(n.rows[j])
+			}
+		}
+	}
+	return product
+}
+
+// Multiplies m by the column vector v
+func (m *Matrix) MulVector(v Vector) Vector {
+	if len(v) != len(NewVector(m.Cols)) {
+		panic("gf2: vector length doesn't match the matrix")
+	}
+	result := NewVector(m.Rows)
+	for i := range m.rows {
+		result.SetBit(i, m.rows[i].Dot(v))
+	}
+	return result
+}
+
+// Brings a copy of m to reduced row echelon form with Gaussian elimination.
+// Returns the reduced matrix and the column of the pivot in each nonzero row;
+// the rank is the number of pivots.
+func (m *Matrix) RowReduce() (*Matrix, []int) {
+	r := m.Copy()
+	var pivots []int
+
+	row := 0
+	for col := 0; col < r.Cols && row < r.Rows; col++ {
+		pivot := -1
+		for i := row; i < r.Rows; i++ {
+			if r.Get(i, col) == 1 {
+				pivot = i
+				break
+			}
+		}
+		if pivot < 0 {
+			continue
+		}
+
+		r.rows[row], r.rows[pivot] = r.rows[pivot], r.rows[row]
+		for i := 0; i < r.Rows; i++ {
+			if i != row && r.Get(i, col) == 1 {
+				r.rows[i].Xor(r.rows[row])
+			}
+		}
+		pivots = append(pivots, col)
+		row++
+	}
+
+	return r, pivots
+}
+
+func (m *Matrix) Rank() int {
+	_, pivots := m.RowReduce()
+	return len(pivots)
+}
+
+// A basis for the kernel of m, the vectors x with m*x = 0. The basis vectors
+// are the columns of the result, so m.Mul(m.Kernel()) is zero.
+//
+// Each free column of the reduced matrix gives one basis vector: set that
+// variable to 1, the other free variables to 0, and solve for the pivots.
+func (m *Matrix) Kernel() *Matrix {
+	r, pivots := m.RowReduce()
+
+	isPivot := make([]bool, m.Cols)
+	for _, col := range pivots {
+		isPivot[col] = true
+	}
+
+	var free []int
+	for col := 0; col < m.Cols; col++ {
+		if !isPivot[col] {
+			free = append(free, col)
+		}
+	}
+
+	basis := NewMatrix(m.Cols, len(free))
+	for k, f := range free {
+		basis.Set(f, k, 1)
+		for i, p := range pivots {
+			if r.Get(i, f) == 1 {
+				basis.Set(p, k, 1)
+			}
+		}
+	}
+	return basis
+}
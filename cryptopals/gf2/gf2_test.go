@@ -0,0 +1,100 @@
+package gf2
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/gf128"
+)
+
+func randomMatrix(rows, cols int) *Matrix {
+	m := NewMatrix(rows, cols)
+	for i := 0; i < rows; i++ {
+		for j := 0; j < cols; j++ {
+			m.Set(i, j, uint(rand.Intn(2)))
+		}
+	}
+	return m
+}
+
+func TestVectorBits(t *testing.T) {
+	v := NewVector(100)
+	v.SetBit(0, 1)
+	v.SetBit(99, 1)
+	v.FlipBit(64)
+	if v[0] != 1<<63 || v[1] != 1<<63|1<<28 {
+		t.Errorf("Unexpected words %x", v)
+	}
+	v.SetBit(99, 0)
+	if v.Bit(99) != 0 || v.Bit(64) != 1 {
+		t.Error("Bits weren't set")
+	}
+}
+
+func TestMulIdentity(t *testing.T) {
+	m := randomMatrix(70, 90)
+	if !Identity(70).Mul(m).Equal(m) || !m.Mul(Identity(90)).Equal(m) {
+		t.Error("Multiplying by the identity changed the matrix")
+	}
+}
+
+func TestTranspose(t *testing.T) {
+	a, b := randomMatrix(10, 70), randomMatrix(70, 20)
+	if !a.Mul(b).Transpose().Equal(b.Transpose().Mul(a.Transpose())) {
+		t.Error("(AB)^T != B^T A^T")
+	}
+}
+
+func TestRowReduce(t *testing.T) {
+	m := NewMatrix(3, 3)
+	// Rows 1 1 0, 0 1 1, 1 0 1: the third is the sum of the other two
+	for _, e := range [][2]int{{0, 0}, {0, 1}, {1, 1}, {1, 2}, {2, 0}, {2, 2}} {
+		m.Set(e[0], e[1], 1)
+	}
+
+	r, pivots := m.RowReduce()
+	if len(pivots) != 2 || pivots[0] != 0 || pivots[1] != 1 {
+		t.Fatalf("Unexpected pivots %v", pivots)
+	}
+	expected := NewMatrix(3, 3)
+	for _, e := range [][2]int{{0, 0}, {0, 2}, {1, 1}, {1, 2}} {
+		expected.Set(e[0], e[1], 1)
+	}
+	if !r.Equal(expected) {
+		t.Errorf("Unexpected reduced matrix %v", r.rows)
+	}
+}
+
+func TestKernel(t *testing.T) {
+	m := randomMatrix(100, 150)
+	kernel := m.Kernel()
+
+	if kernel.Cols != 150-m.Rank() {
+		t.Errorf("Expected a kernel of dimension %d, got %d", 150-m.Rank(), kernel.Cols)
+	}
+	if kernel.Rank() != kernel.Cols {
+		t.Error("The kernel basis isn't linearly independent")
+	}
+	if m.Mul(kernel).Rank() != 0 {
+		t.Error("The kernel basis isn't in the kernel")
+	}
+}
+
+func TestMulMatrix(t *testing.T) {
+	c, _ := gf128.Random()
+	y, _ := gf128.Random()
+
+	product := VectorElement(MulMatrix(c).MulVector(ElementVector(y)))
+	if product != c.Mul(y) {
+		t.Errorf("Expected %x, got %x", c.Mul(y).Bytes(), product.Bytes())
+	}
+}
+
+func TestSquareMatrix(t *testing.T) {
+	y, _ := gf128.Random()
+
+	square := VectorElement(SquareMatrix().MulVector(ElementVector(y)))
+	if square != y.Square() {
+		t.Errorf("Expected %x, got %x", y.Square().Bytes(), square.Bytes())
+	}
+}
@@ -0,0 +1,85 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+)
+
+// Infinite garble extension, best known from Telegram's MTProto:
+//
+//	C_i = E(P_i ^ C_i-1) ^ P_i-1
+//	P_i = D(C_i ^ P_i-1) ^ C_i-1
+//
+// The IV is two blocks long: C_0 followed by P_0, like OpenSSL's.
+
+type ige struct {
+	b         cipher.Block
+	blockSize int
+	// C_i-1 and P_i-1
+	prevCiphertext []byte
+	prevPlaintext  []byte
+}
+
+type igeDecrypter ige
+type igeEncrypter ige
+
+func newIGE(b cipher.Block, iv []byte) *ige {
+	blockSize := b.BlockSize()
+	if len(iv) != 2*blockSize {
+		panic("cryptopals: IGE IV must be two blocks long")
+	}
+	return &ige{
+		b:              b,
+		blockSize:      blockSize,
+		prevCiphertext: append([]byte{}, iv[:blockSize]...),
+		prevPlaintext:  append([]byte{}, iv[blockSize:]...),
+	}
+}
+
+func NewIGEDecrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return (*igeDecrypter)(newIGE(block, iv))
+}
+
+func NewIGEEncrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return (*igeEncrypter)(newIGE(block, iv))
+}
+
+func (x *igeDecrypter) BlockSize() int { return x.blockSize }
+
+func (x *igeEncrypter) BlockSize() int { return x.blockSize }
+
+// Runs one direction of IGE. `in` is P_i when encrypting and C_i when
+// decrypting, and `prevIn`/`prevOut` hold the previous block of each.
+func igeCrypt(crypt func(dst, src []byte), blockSize int, dst, src, prevIn, prevOut []byte) {
+	if len(src)%blockSize != 0 {
+		panic("crypto/cipher: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	in := make([]byte, blockSize)
+	tmp := make([]byte, blockSize)
+	for len(src) > 0 {
+		copy(in, src[:blockSize])
+		copy(tmp, in)
+		if err := FixedXOR(tmp, prevOut); err != nil {
+			panic(err)
+		}
+		crypt(dst, tmp)
+		if err := FixedXOR(dst[:blockSize], prevIn); err != nil {
+			panic(err)
+		}
+
+		copy(prevIn, in)
+		copy(prevOut, dst[:blockSize])
+		src = src[blockSize:]
+		dst = dst[blockSize:]
+	}
+}
+
+func (x *igeDecrypter) CryptBlocks(dst, src []byte) {
+	igeCrypt(x.b.Decrypt, x.blockSize, dst, src, x.prevCiphertext, x.prevPlaintext)
+}
+
+func (x *igeEncrypter) CryptBlocks(dst, src []byte) {
+	igeCrypt(x.b.Encrypt, x.blockSize, dst, src, x.prevPlaintext, x.prevCiphertext)
+}
@@ -0,0 +1,59 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/aes"
+	"testing"
+)
+
+func TestIGETestVector(t *testing.T) {
+	// From OpenSSL's IGE tests
+	key := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	iv := decodeHex(t, "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f")
+	plaintext := make([]byte, 32)
+	expected := decodeHex(t, "1a8519a6557be652e9da8e43da4ef4453cf456b4ca488aa383c79c98b34797cb")
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted := make([]byte, len(plaintext))
+	NewIGEEncrypter(block, iv).CryptBlocks(encrypted, plaintext)
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+
+	decrypted := make([]byte, len(encrypted))
+	NewIGEDecrypter(block, iv).CryptBlocks(decrypted, encrypted)
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %x, got %x", plaintext, decrypted)
+	}
+}
+
+func TestAESIGEEncryptDecrypt(t *testing.T) {
+	key, iv := randomBytes(t, 16), randomBytes(t, 32)
+	plaintext := []byte("this is some plaintext which spans a few blocks")
+
+	encrypted, err := EncryptAESIGE(plaintext, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := DecryptAESIGE(encrypted, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(MaybePKCS7Unpad(decrypted), plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, decrypted)
+	}
+}
+
+func TestAESIGEBadIV(t *testing.T) {
+	key := randomBytes(t, 16)
+	// One block of IV is enough for CBC, but not for IGE
+	if _, err := EncryptAESIGE([]byte("data"), key, randomBytes(t, 16)); err == nil {
+		t.Error("Expected an error for a one block IV")
+	}
+	if _, err := DecryptAESIGE(randomBytes(t, 32), key, randomBytes(t, 16)); err == nil {
+		t.Error("Expected an error for a one block IV")
+	}
+}
@@ -0,0 +1,258 @@
+// Package lll implements Lenstra-Lenstra-Lovász lattice basis reduction with
+// exact rational arithmetic.
+//
+// Everything is done with big.Rat, so there's no floating point error to
+// worry about, at the cost of being slow for large lattices. The basis
+// vectors must be linearly independent.
+package lll
+
+import (
+	"math/big"
+)
+
+var half = big.NewRat(1, 2)
+
+// The usual choice of delta for the Lovász condition
+var DefaultDelta = big.NewRat(99, 100)
+
+type Vector []*big.Rat
+
+// A vector with integer coordinates
+func NewVector(values ...*big.Int) Vector {
+	v := make(Vector, len(values))
+	for i, x := range values {
+		v[i] = new(big.Rat).SetInt(x)
+	}
+	return v
+}
+
+// A vector with small integer coordinates
+func NewVectorInt64(values ...int64) Vector {
+	v := make(Vector, len(values))
+	for i, x := range values {
+		v[i] = big.NewRat(x, 1)
+	}
+	return v
+}
+
+func (v Vector) Copy() Vector {
+	c := make(Vector, len(v))
+	for i, x := range v {
+		c[i] = new(big.Rat).Set(x)
+	}
+	return c
+}
+
+func (v Vector) Equal(u Vector) bool {
+	if len(v) != len(u) {
+		return false
+	}
+	for i := range v {
+		if v[i].Cmp(u[i]) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func Dot(u, v Vector) *big.Rat {
+	sum := new(big.Rat)
+	t := new(big.Rat)
+	for i := range u {
+		sum.Add(sum, t.Mul(u[i], v[i]))
+	}
+	return sum
+}
+
+// u - c*v
+func sub(u Vector, c *big.Rat, v Vector) Vector {
+	result := make(Vector, len(u))
+	t := new(big.Rat)
+	for i := range u {
+		result[i] = new(big.Rat).Sub(u[i], t.Mul(c, v[i]))
+	}
+	return result
+}
+
+// Rounds x to the nearest integer, with halves rounded up
+func round(x *big.Rat) *big.Rat {
+	y := new(big.Rat).Add(x, half)
+	// floor(y) = (num - (num mod den)) / den, which big.Int.Div gives us
+	// directly since it uses Euclidean division
+	q := new(big.Int).Div(y.Num(), y.Denom())
+	return new(big.Rat).SetInt(q)
+}
+
+// Computes the Gram-Schmidt orthogonalization of `basis`, without
+// normalizing:
+//
+//	q[i] = b[i] - sum(mu[i][j] * q[j] for j < i)
+//	mu[i][j] = <b[i], q[j]> / <q[j], q[j]>
+func GramSchmidt(basis []Vector) []Vector {
+	q, _, _ := gramSchmidt(basis)
+	return q
+}
+
+// Also returns the mu coefficients and the squared lengths of q
+func gramSchmidt(basis []Vector) ([]Vector, [][]*big.Rat, []*big.Rat) {
+	n := len(basis)
+	q := make([]Vector, n)
+	mu := make([][]*big.Rat, n)
+	norms := make([]*big.Rat, n)
+
+	for i, b := range basis {
+		mu[i] = make([]*big.Rat, n)
+		q[i] = b.Copy()
+		for j := 0; j < i; j++ {
+			mu[i][j] = new(big.Rat).Quo(Dot(b, q[j]), norms[j])
+			q[i] = sub(q[i], mu[i][j], q[j])
+		}
+		norms[i] = Dot(q[i], q[i])
+	}
+
+	return q, mu, norms
+}
+
+// The state of a reduction: the basis, the Gram-Schmidt coefficients, and
+// the squared lengths of the Gram-Schmidt vectors, which are kept up to date
+// as the basis changes instead of recomputing them from scratch
+type reduction struct {
+	b     []Vector
+	mu    [][]*big.Rat
+	norms []*big.Rat
+}
+
+func newReduction(basis []Vector) *reduction {
+	b := make([]Vector, len(basis))
+	for i, v := range basis {
+		b[i] = v.Copy()
+	}
+	_, mu, norms := gramSchmidt(b)
+	return &reduction{b, mu, norms}
+}
+
+// Makes |mu[k][l]| <= 1/2 by subtracting the nearest integer multiple of b[l]
+// from b[k]
+func (r *reduction) reduce(k, l int) {
+	if new(big.Rat).Abs(r.mu[k][l]).Cmp(half) <= 0 {
+		return
+	}
+
+	c := round(r.mu[k][l])
+	r.b[k] = sub(r.b[k], c, r.b[l])
+
+	t := new(big.Rat)
+	for j := 0; j < l; j++ {
+		r.mu[k][j].Sub(r.mu[k][j], t.Mul(c, r.mu[l][j]))
+	}
+	r.mu[k][l].Sub(r.mu[k][l], c)
+}
+
+// Swaps b[k] and b[k-1], and updates the Gram-Schmidt data to match
+func (r *reduction) swap(k int) {
+	r.b[k], r.b[k-1] = r.b[k-1], r.b[k]
+	for j := 0; j < k-1; j++ {
+		r.mu[k][j], r.mu[k-1][j] = r.mu[k-1][j], r.mu[k][j]
+	}
+
+	m := r.mu[k][k-1]
+
+	// B = |q[k]|^2 + m^2 * |q[k-1]|^2 is the new |q[k-1]|^2
+	B := new(big.Rat).Mul(m, m)
+	B.Mul(B, r.norms[k-1])
+	B.Add(B, r.norms[k])
+
+	newMu := new(big.Rat).Mul(m, r.norms[k-1])
+	newMu.Quo(newMu, B)
+
+	r.norms[k].Mul(r.norms[k], r.norms[k-1])
+	r.norms[k].Quo(r.norms[k], B)
+	r.norms[k-1] = B
+	r.mu[k][k-1] = newMu
+
+	t := new(big.Rat)
+	for i := k + 1; i < len(r.b); i++ {
+		old := new(big.Rat).Set(r.mu[i][k])
+		r.mu[i][k] = new(big.Rat).Sub(r.mu[i][k-1], t.Mul(m, old))
+		r.mu[i][k-1] = old.Add(old, t.Mul(newMu, r.mu[i][k]))
+	}
+}
+
+// Size reduces `basis`, so that every |mu[i][j]| <= 1/2. The lattice and the
+// Gram-Schmidt vectors stay the same.
+func SizeReduce(basis []Vector) []Vector {
+	r := newReduction(basis)
+	for k := 1; k < len(r.b); k++ {
+		for l := k - 1; l >= 0; l-- {
+			r.reduce(k, l)
+		}
+	}
+	return r.b
+}
+
+// Reduces `basis` with the LLL algorithm. Each vector is size reduced, and
+// neighbouring vectors are swapped until they satisfy the Lovász condition:
+//
+//	|q[k]|^2 >= (delta - mu[k][k-1]^2) * |q[k-1]|^2
+//
+// delta must be in (1/4, 1]. The first vector of the result is guaranteed to
+// be within a factor of (1 / (delta - 1/4))^((n-1)/2) of the shortest vector
+// in the lattice, and is usually much closer.
+func Reduce(basis []Vector, delta *big.Rat) []Vector {
+	r := newReduction(basis)
+	t := new(big.Rat)
+
+	k := 1
+	for k < len(r.b) {
+		r.reduce(k, k-1)
+
+		// delta - mu[k][k-1]^2
+		bound := new(big.Rat).Mul(r.mu[k][k-1], r.mu[k][k-1])
+		bound.Sub(delta, bound)
+
+		if r.norms[k].Cmp(t.Mul(bound, r.norms[k-1])) < 0 {
+			r.swap(k)
+			if k > 1 {
+				k--
+			}
+		} else {
+			for l := k - 2; l >= 0; l-- {
+				r.reduce(k, l)
+			}
+			k++
+		}
+	}
+
+	return r.b
+}
+
+// Whether every |mu[i][j]| <= 1/2
+func IsSizeReduced(basis []Vector) bool {
+	_, mu, _ := gramSchmidt(basis)
+	for i := range basis {
+		for j := 0; j < i; j++ {
+			if new(big.Rat).Abs(mu[i][j]).Cmp(half) > 0 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Whether `basis` is size reduced and satisfies the Lovász condition for
+// `delta`
+func IsReduced(basis []Vector, delta *big.Rat) bool {
+	if !IsSizeReduced(basis) {
+		return false
+	}
+
+	_, mu, norms := gramSchmidt(basis)
+	for k := 1; k < len(basis); k++ {
+		bound := new(big.Rat).Mul(mu[k][k-1], mu[k][k-1])
+		bound.Sub(delta, bound)
+		if norms[k].Cmp(bound.Mul(bound, norms[k-1])) < 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package lll
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+)
+
+func TestGramSchmidt(t *testing.T) {
+	q := GramSchmidt([]Vector{
+		NewVectorInt64(3, 1),
+		NewVectorInt64(2, 2),
+	})
+
+	// q2 = (2, 2) - 8/10 * (3, 1)
+	expected := []Vector{
+		NewVectorInt64(3, 1),
+		{big.NewRat(-2, 5), big.NewRat(6, 5)},
+	}
+	for i := range expected {
+		if !q[i].Equal(expected[i]) {
+			t.Errorf("Expected q[%d] = %v, got %v", i, expected[i], q[i])
+		}
+	}
+}
+
+func TestGramSchmidtIsOrthogonal(t *testing.T) {
+	q := GramSchmidt([]Vector{
+		NewVectorInt64(1, 1, 1),
+		NewVectorInt64(-1, 0, 2),
+		NewVectorInt64(3, 5, 6),
+	})
+	for i := range q {
+		for j := 0; j < i; j++ {
+			if Dot(q[i], q[j]).Sign() != 0 {
+				t.Errorf("q[%d] and q[%d] aren't orthogonal", i, j)
+			}
+		}
+	}
+}
+
+func TestSizeReduce(t *testing.T) {
+	basis := []Vector{
+		NewVectorInt64(1, 0),
+		NewVectorInt64(5, 1),
+	}
+	if IsSizeReduced(basis) {
+		t.Fatal("The basis shouldn't be size reduced yet")
+	}
+
+	reduced := SizeReduce(basis)
+	if !IsSizeReduced(reduced) {
+		t.Error("The basis isn't size reduced")
+	}
+	if !reduced[1].Equal(NewVectorInt64(0, 1)) {
+		t.Errorf("Expected (0, 1), got %v", reduced[1])
+	}
+
+	// The original basis isn't touched
+	if !basis[1].Equal(NewVectorInt64(5, 1)) {
+		t.Errorf("SizeReduce modified its input")
+	}
+}
+
+func TestSizeReduceKeepsGramSchmidt(t *testing.T) {
+	basis := []Vector{
+		NewVectorInt64(1, 1, 1),
+		NewVectorInt64(-1, 0, 2),
+		NewVectorInt64(3, 5, 6),
+	}
+	before := GramSchmidt(basis)
+	after := GramSchmidt(SizeReduce(basis))
+	for i := range before {
+		if !before[i].Equal(after[i]) {
+			t.Errorf("Size reduction changed q[%d] from %v to %v", i, before[i], after[i])
+		}
+	}
+}
+
+func TestReduce(t *testing.T) {
+	// The example from Wikipedia
+	reduced := Reduce([]Vector{
+		NewVectorInt64(1, 1, 1),
+		NewVectorInt64(-1, 0, 2),
+		NewVectorInt64(3, 5, 6),
+	}, big.NewRat(3, 4))
+
+	expected := []Vector{
+		NewVectorInt64(0, 1, 0),
+		NewVectorInt64(1, 0, 1),
+		NewVectorInt64(-1, 0, 2),
+	}
+	for i := range expected {
+		if !reduced[i].Equal(expected[i]) {
+			t.Errorf("Expected b[%d] = %v, got %v", i, expected[i], reduced[i])
+		}
+	}
+}
+
+// The determinant of a square matrix with integer entries, by cofactor
+// expansion. Only for tiny matrices.
+func determinant(m []Vector) *big.Rat {
+	if len(m) == 1 {
+		return new(big.Rat).Set(m[0][0])
+	}
+	det := new(big.Rat)
+	for col := range m {
+		var minor []Vector
+		for _, row := range m[1:] {
+			var r Vector
+			r = append(r, row[:col]...)
+			r = append(r, row[col+1:]...)
+			minor = append(minor, r)
+		}
+		term := new(big.Rat).Mul(m[0][col], determinant(minor))
+		if col%2 == 1 {
+			term.Neg(term)
+		}
+		det.Add(det, term)
+	}
+	return det
+}
+
+func TestReduceRandom(t *testing.T) {
+	for trial := 0; trial < 5; trial++ {
+		basis := make([]Vector, 5)
+		for i := range basis {
+			var values []*big.Int
+			for j := 0; j < 5; j++ {
+				x, err := rand.Int(rand.Reader, big.NewInt(1<<20))
+				if err != nil {
+					t.Fatal(err)
+				}
+				values = append(values, x)
+			}
+			basis[i] = NewVector(values...)
+		}
+		det := determinant(basis)
+		if det.Sign() == 0 {
+			continue
+		}
+
+		reduced := Reduce(basis, DefaultDelta)
+		if !IsReduced(reduced, DefaultDelta) {
+			t.Errorf("The basis isn't LLL reduced")
+		}
+
+		// Same lattice, so the same determinant up to sign
+		if after := determinant(reduced); new(big.Rat).Abs(after).Cmp(new(big.Rat).Abs(det)) != 0 {
+			t.Errorf("Reduction changed the determinant from %v to %v", det, after)
+		}
+	}
+}
@@ -28,6 +28,12 @@ const (
 	_Init3 = 0x10325476
 )
 
+// InitialState returns the state of a new MD4 hash, for use with NewExtension
+// and the round functions.
+func InitialState() [4]uint32 {
+	return [4]uint32{_Init0, _Init1, _Init2, _Init3}
+}
+
 // digest represents the partial evaluation of a checksum.
 type digest struct {
 	s   [4]uint32
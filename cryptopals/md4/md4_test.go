@@ -69,3 +69,35 @@ func TestGolden(t *testing.T) {
 		}
 	}
 }
+
+// The exported step functions and tables rebuild the compression function
+func TestSteps(t *testing.T) {
+	block := make([]byte, _Chunk)
+	for i := range block {
+		block[i] = byte(i * 7)
+	}
+
+	dig := &digest{s: InitialState()}
+	_Block(dig, block)
+
+	X := Words(block)
+	s := InitialState()
+	a, b, c, d := s[0], s[1], s[2], s[3]
+	for i := 0; i < 16; i++ {
+		a = Round1Step(a, b, c, d, X[i], Round1Shift(i))
+		a, b, c, d = d, a, b, c
+	}
+	for i := 0; i < 16; i++ {
+		a = Round2Step(a, b, c, d, X[Round2Index(i)], Round2Shift(i))
+		a, b, c, d = d, a, b, c
+	}
+	for i := 0; i < 16; i++ {
+		a = Round3Step(a, b, c, d, X[Round3Index(i)], Round3Shift(i))
+		a, b, c, d = d, a, b, c
+	}
+
+	result := [4]uint32{s[0] + a, s[1] + b, s[2] + c, s[3] + d}
+	if result != dig.s {
+		t.Errorf("Expected %x, got %x", dig.s, result)
+	}
+}
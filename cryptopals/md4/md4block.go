@@ -8,12 +8,83 @@
 
 package md4
 
-var shift1 = []uint{3, 7, 11, 19}
-var shift2 = []uint{3, 5, 9, 13}
-var shift3 = []uint{3, 9, 11, 15}
+import "math/bits"
 
-var xIndex2 = []uint{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
-var xIndex3 = []uint{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+var shift1 = [4]uint{3, 7, 11, 19}
+var shift2 = [4]uint{3, 5, 9, 13}
+var shift3 = [4]uint{3, 9, 11, 15}
+
+var xIndex2 = [16]uint{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+var xIndex3 = [16]uint{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+
+// Round1Shift returns the rotation used in step i of round 1.
+func Round1Shift(i int) uint { return shift1[i%4] }
+
+// Round2Shift returns the rotation used in step i of round 2.
+func Round2Shift(i int) uint { return shift2[i%4] }
+
+// Round3Shift returns the rotation used in step i of round 3.
+func Round3Shift(i int) uint { return shift3[i%4] }
+
+// Round2Index returns which message word step i of round 2 uses. Round 1
+// uses them in order.
+func Round2Index(i int) int { return int(xIndex2[i]) }
+
+// Round3Index returns which message word step i of round 3 uses.
+func Round3Index(i int) int { return int(xIndex3[i]) }
+
+// The constants added in rounds 2 and 3.
+const (
+	Round2Constant = 0x5a827999
+	Round3Constant = 0x6ed9eba1
+)
+
+// F is the round 1 function: if x then y else z.
+func F(x, y, z uint32) uint32 { return ((y ^ z) & x) ^ z }
+
+// G is the round 2 function: the majority of x, y and z.
+func G(x, y, z uint32) uint32 { return (x & y) | (x & z) | (y & z) }
+
+// H is the round 3 function: the parity of x, y and z.
+func H(x, y, z uint32) uint32 { return x ^ y ^ z }
+
+// Round1Step returns the new value of a after one step of round 1:
+// (a + F(b, c, d) + x) <<< s
+func Round1Step(a, b, c, d, x uint32, s uint) uint32 {
+	return bits.RotateLeft32(a+F(b, c, d)+x, int(s))
+}
+
+// Round2Step returns the new value of a after one step of round 2:
+// (a + G(b, c, d) + x + 0x5a827999) <<< s
+func Round2Step(a, b, c, d, x uint32, s uint) uint32 {
+	return bits.RotateLeft32(a+G(b, c, d)+x+Round2Constant, int(s))
+}
+
+// Round3Step returns the new value of a after one step of round 3:
+// (a + H(b, c, d) + x + 0x6ed9eba1) <<< s
+func Round3Step(a, b, c, d, x uint32, s uint) uint32 {
+	return bits.RotateLeft32(a+H(b, c, d)+x+Round3Constant, int(s))
+}
+
+// Words returns the sixteen little-endian message words of a 64 byte block.
+func Words(p []byte) [16]uint32 {
+	var X [16]uint32
+	j := 0
+	for i := 0; i < 16; i++ {
+		X[i] = uint32(p[j]) | uint32(p[j+1])<<8 | uint32(p[j+2])<<16 | uint32(p[j+3])<<24
+		j += 4
+	}
+	return X
+}
+
+// Bytes returns the 64 byte block made up of the message words X.
+func Bytes(X [16]uint32) []byte {
+	p := make([]byte, 0, _Chunk)
+	for _, x := range X {
+		p = append(p, byte(x), byte(x>>8), byte(x>>16), byte(x>>24))
+	}
+	return p
+}
 
 func _Block(dig *digest, p []byte) int {
 	a := dig.s[0]
@@ -21,15 +92,10 @@ func _Block(dig *digest, p []byte) int {
 	c := dig.s[2]
 	d := dig.s[3]
 	n := 0
-	var X [16]uint32
 	for len(p) >= _Chunk {
 		aa, bb, cc, dd := a, b, c, d
 
-		j := 0
-		for i := 0; i < 16; i++ {
-			X[i] = uint32(p[j]) | uint32(p[j+1])<<8 | uint32(p[j+2])<<16 | uint32(p[j+3])<<24
-			j += 4
-		}
+		X := Words(p)
 
 		// If this needs to be made faster in the future,
 		// the usual trick is to unroll each of these
@@ -44,31 +110,19 @@ func _Block(dig *digest, p []byte) int {
 
 		// Round 1.
 		for i := uint(0); i < 16; i++ {
-			x := i
-			s := shift1[i%4]
-			f := ((c ^ d) & b) ^ d
-			a += f + X[x]
-			a = a<<s | a>>(32-s)
+			a = Round1Step(a, b, c, d, X[i], shift1[i%4])
 			a, b, c, d = d, a, b, c
 		}
 
 		// Round 2.
 		for i := uint(0); i < 16; i++ {
-			x := xIndex2[i]
-			s := shift2[i%4]
-			g := (b & c) | (b & d) | (c & d)
-			a += g + X[x] + 0x5a827999
-			a = a<<s | a>>(32-s)
+			a = Round2Step(a, b, c, d, X[xIndex2[i]], shift2[i%4])
 			a, b, c, d = d, a, b, c
 		}
 
 		// Round 3.
 		for i := uint(0); i < 16; i++ {
-			x := xIndex3[i]
-			s := shift3[i%4]
-			h := b ^ c ^ d
-			a += h + X[x] + 0x6ed9eba1
-			a = a<<s | a>>(32-s)
+			a = Round3Step(a, b, c, d, X[xIndex3[i]], shift3[i%4])
 			a, b, c, d = d, a, b, c
 		}
 
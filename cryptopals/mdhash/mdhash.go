@@ -0,0 +1,163 @@
+// Package mdhash implements a toy Merkle-Damgård hash function with a tiny,
+// configurable state, for attacking iterated hashes in Set 7.
+//
+// The compression function encrypts each message block with AES-128, keyed by
+// the current state (zero padded to the size of an AES key), and truncates the
+// result back to the size of the state:
+//
+//	H' = E(pad(H), M)[:len(H)]
+//
+// Messages are padded like MD4 and SHA-1 are, with a 1 bit, 0 bits, and the
+// message length in bits.
+package mdhash
+
+import (
+	"crypto/aes"
+	"fmt"
+	"hash"
+	"math"
+)
+
+// The blocksize of the hash in bytes.
+const BlockSize = aes.BlockSize
+
+// The largest state the hash supports in bytes, since the state is used as an
+// AES-128 key.
+const MaxSize = 16
+
+// Initial state, truncated to the size of the hash
+var iv = []byte{
+	0x67, 0x45, 0x23, 0x01, 0xef, 0xcd, 0xab, 0x89,
+	0x98, 0xba, 0xdc, 0xfe, 0x10, 0x32, 0x54, 0x76,
+}
+
+// digest represents the partial evaluation of a checksum.
+type digest struct {
+	h   []byte
+	x   [BlockSize]byte
+	nx  int
+	len uint64
+}
+
+func (d *digest) Reset() {
+	copy(d.h, iv)
+	d.nx = 0
+	d.len = 0
+}
+
+func checkSize(size int) {
+	if size < 1 || size > MaxSize {
+		panic(fmt.Sprintf("mdhash: invalid state size %d", size))
+	}
+}
+
+// New returns a new hash.Hash with a state of `size` bytes.
+func New(size int) hash.Hash {
+	checkSize(size)
+	d := &digest{h: make([]byte, size)}
+	d.Reset()
+	return d
+}
+
+// NewExtension returns a new hash.Hash which starts from the state `h`, as if
+// `length` bytes had already been written to it. The size of the hash is the
+// size of the state.
+func NewExtension(h []byte, length uint64) hash.Hash {
+	checkSize(len(h))
+	d := &digest{h: append([]byte{}, h...)}
+	d.len = length
+	return d
+}
+
+// IV returns the initial state of a hash with a state of `size` bytes.
+func IV(size int) []byte {
+	checkSize(size)
+	return append([]byte{}, iv[:size]...)
+}
+
+// Compress runs the compression function on a single block of `block` with
+// the state `h`, and returns the new state.
+func Compress(h, block []byte) []byte {
+	key := make([]byte, aes.BlockSize)
+	copy(key, h)
+	cipher, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+
+	out := make([]byte, BlockSize)
+	cipher.Encrypt(out, block[:BlockSize])
+	return out[:len(h)]
+}
+
+// Pad returns the padding which is appended to a message of `length` bytes
+// before it's hashed.
+func Pad(length uint64) []byte {
+	// Add a 1 bit and 0 bits until 8 bytes mod 16.
+	var tmp [BlockSize + 8]byte
+	tmp[0] = 0x80
+	n := BlockSize + 8 - length%BlockSize
+	if length%BlockSize < 8 {
+		n = 8 - length%BlockSize
+	}
+
+	// Length in bits.
+	bits := length << 3
+	for i := uint(0); i < 8; i++ {
+		tmp[n+uint64(i)] = byte(bits >> (56 - 8*i))
+	}
+	return tmp[:n+8]
+}
+
+// CollisionWork returns the number of calls to the compression function we
+// expect to make before finding a collision with a state of `size` bytes, from
+// the birthday bound.
+func CollisionWork(size int) float64 {
+	return math.Exp2(float64(8*size) / 2)
+}
+
+func (d *digest) Size() int { return len(d.h) }
+
+func (d *digest) BlockSize() int { return BlockSize }
+
+func (d *digest) Write(p []byte) (nn int, err error) {
+	nn = len(p)
+	d.len += uint64(nn)
+	if d.nx > 0 {
+		n := copy(d.x[d.nx:], p)
+		d.nx += n
+		if d.nx == BlockSize {
+			d.h = Compress(d.h, d.x[:])
+			d.nx = 0
+		}
+		p = p[n:]
+	}
+	for len(p) >= BlockSize {
+		d.h = Compress(d.h, p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	if len(p) > 0 {
+		d.nx = copy(d.x[:], p)
+	}
+	return
+}
+
+func (d0 *digest) Sum(in []byte) []byte {
+	// Make a copy of d0 so that caller can keep writing and summing.
+	d := *d0
+	d.h = append([]byte{}, d0.h...)
+
+	d.Write(Pad(d.len))
+	if d.nx != 0 {
+		panic("d.nx != 0")
+	}
+
+	return append(in, d.h...)
+}
+
+// Sum returns the checksum of the data with a state of `size` bytes.
+func Sum(size int, data []byte) []byte {
+	d := New(size)
+	d.Write(data)
+	return d.Sum(nil)
+}
@@ -0,0 +1,73 @@
+package mdhash
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPadLength(t *testing.T) {
+	for length := uint64(0); length < 3*BlockSize; length++ {
+		pad := Pad(length)
+		if (length+uint64(len(pad)))%BlockSize != 0 {
+			t.Errorf("Padding for %d bytes is not block aligned: %d bytes", length, len(pad))
+		}
+		if pad[0] != 0x80 || len(pad) < 9 || len(pad) > BlockSize+8 {
+			t.Errorf("Invalid padding for %d bytes: %x", length, pad)
+		}
+	}
+}
+
+func TestSumMatchesCompress(t *testing.T) {
+	message := []byte("YELLOW SUBMARINEyellow submarine")
+	for size := 1; size <= MaxSize; size++ {
+		h := IV(size)
+		padded := append(append([]byte{}, message...), Pad(uint64(len(message)))...)
+		for i := 0; i < len(padded); i += BlockSize {
+			h = Compress(h, padded[i:i+BlockSize])
+		}
+		if result := Sum(size, message); !bytes.Equal(result, h) {
+			t.Errorf("Size %d: expected %x, got %x", size, h, result)
+		}
+	}
+}
+
+func TestIncrementalWrites(t *testing.T) {
+	message := []byte("The days of the digital watch are numbered.  -Tom Stoppard")
+	expected := Sum(4, message)
+
+	d := New(4)
+	for i := range message {
+		d.Write(message[i : i+1])
+		// Summing in the middle shouldn't affect the result
+		d.Sum(nil)
+	}
+	if result := d.Sum(nil); !bytes.Equal(result, expected) {
+		t.Errorf("Expected %x, got %x", expected, result)
+	}
+
+	d.Reset()
+	d.Write(message)
+	if result := d.Sum(nil); !bytes.Equal(result, expected) {
+		t.Errorf("Expected %x after Reset, got %x", expected, result)
+	}
+}
+
+func TestNewExtension(t *testing.T) {
+	prefix := []byte("YELLOW SUBMARINE")
+	suffix := []byte("this is the rest of the message")
+
+	state := Compress(IV(3), prefix)
+	d := NewExtension(state, uint64(len(prefix)))
+	d.Write(suffix)
+
+	expected := Sum(3, append(prefix, suffix...))
+	if result := d.Sum(nil); !bytes.Equal(result, expected) {
+		t.Errorf("Expected %x, got %x", expected, result)
+	}
+}
+
+func TestCollisionWork(t *testing.T) {
+	if work := CollisionWork(2); work != 256 {
+		t.Errorf("Expected 256 calls for a 16-bit state, got %f", work)
+	}
+}
@@ -0,0 +1,39 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+)
+
+// Output feedback mode. The keystream is the IV encrypted over and over, so
+// it doesn't depend on the message at all, and encrypting and decrypting are
+// the same operation.
+
+type ofb struct {
+	b         cipher.Block
+	blockSize int
+	keystream []byte
+	used      int
+}
+
+func NewOFB(b cipher.Block, iv []byte) cipher.Stream {
+	return &ofb{
+		b:         b,
+		blockSize: b.BlockSize(),
+		keystream: append([]byte{}, iv...),
+		used:      b.BlockSize(),
+	}
+}
+
+func (x *ofb) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	for i := range src {
+		if x.used == x.blockSize {
+			x.b.Encrypt(x.keystream, x.keystream)
+			x.used = 0
+		}
+		dst[i] = src[i] ^ x.keystream[x.used]
+		x.used++
+	}
+}
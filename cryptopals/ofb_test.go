@@ -0,0 +1,36 @@
+package cryptopals
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+)
+
+func TestOFBMatchesStdlib(t *testing.T) {
+	key, iv := randomBytes(t, 16), randomBytes(t, 16)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := randomBytes(t, 53)
+
+	expected := make([]byte, len(plaintext))
+	cipher.NewOFB(block, iv).XORKeyStream(expected, plaintext)
+
+	encrypted, err := EncryptAESOFB(plaintext, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+
+	decrypted, err := DecryptAESOFB(encrypted, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %x, got %x", plaintext, decrypted)
+	}
+}
@@ -0,0 +1,191 @@
+package cryptopals
+
+import (
+	"bytes"
+	"errors"
+)
+
+// A block cipher padding scheme
+type Padding interface {
+	// Pads data out to a multiple of blockSize
+	Pad(data []byte, blockSize int) ([]byte, error)
+	// Strips the padding from data, or returns an error if the padding is
+	// invalid
+	Unpad(data []byte, blockSize int) ([]byte, error)
+}
+
+var (
+	// n bytes of value n
+	PKCS7 Padding = pkcs7{}
+	// n-1 zero bytes, then a byte of value n
+	X923 Padding = x923{}
+	// n-1 random bytes, then a byte of value n
+	ISO10126 Padding = iso10126{}
+	// A single 0x80 byte, then zeros
+	ISO7816 Padding = iso7816{}
+	// Zeros, only as many as it takes to fill the last block. It can't tell
+	// padding from trailing zeros in the data, so Unpad can only fail on bad
+	// lengths.
+	ZeroPadding Padding = zeroPadding{}
+)
+
+var (
+	ErrBadBlockSize = errors.New("Invalid block size for padding")
+	ErrBadPadding   = errors.New("Invalid padding")
+)
+
+// The number of pad bytes needed to fill out the last block. Always at least
+// one, so a full block of padding is added to aligned data.
+func padLength(data []byte, blockSize int) int {
+	return blockSize - len(data)%blockSize
+}
+
+// Checks that padded data is a whole number of blocks
+func checkPadded(data []byte, blockSize int) error {
+	if blockSize < 1 {
+		return ErrBadBlockSize
+	}
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return errors.New("Padded data must be a nonzero multiple of the block size")
+	}
+	return nil
+}
+
+// Pads with n-1 bytes from `fill`, then n. Shared by the schemes which end in
+// a length byte.
+func padWithLength(data []byte, blockSize int, fill func(n int) ([]byte, error)) ([]byte, error) {
+	if blockSize < 1 || blockSize > 255 {
+		return nil, ErrBadBlockSize
+	}
+	n := padLength(data, blockSize)
+	pad, err := fill(n - 1)
+	if err != nil {
+		return nil, err
+	}
+	result := append([]byte{}, data...)
+	result = append(result, pad...)
+	return append(result, byte(n)), nil
+}
+
+// Reads the length byte at the end of data and returns the pad bytes before
+// it
+func lengthPadding(data []byte, blockSize int) (n int, fill []byte, err error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return 0, nil, err
+	}
+	n = int(data[len(data)-1])
+	if n == 0 || n > blockSize {
+		return 0, nil, ErrBadPadding
+	}
+	return n, data[len(data)-n : len(data)-1], nil
+}
+
+type pkcs7 struct{}
+
+func (pkcs7) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWithLength(data, blockSize, func(n int) ([]byte, error) {
+		return bytes.Repeat([]byte{byte(n + 1)}, n), nil
+	})
+}
+
+func (pkcs7) Unpad(data []byte, blockSize int) ([]byte, error) {
+	n, fill, err := lengthPadding(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fill, bytes.Repeat([]byte{byte(n)}, n-1)) {
+		return nil, ErrBadPadding
+	}
+	return data[:len(data)-n], nil
+}
+
+type x923 struct{}
+
+func (x923) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWithLength(data, blockSize, func(n int) ([]byte, error) {
+		return make([]byte, n), nil
+	})
+}
+
+func (x923) Unpad(data []byte, blockSize int) ([]byte, error) {
+	n, fill, err := lengthPadding(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(fill, make([]byte, n-1)) {
+		return nil, ErrBadPadding
+	}
+	return data[:len(data)-n], nil
+}
+
+type iso10126 struct{}
+
+func (iso10126) Pad(data []byte, blockSize int) ([]byte, error) {
+	return padWithLength(data, blockSize, GenerateRandomBytes)
+}
+
+// Only the length byte can be checked
+func (iso10126) Unpad(data []byte, blockSize int) ([]byte, error) {
+	n, _, err := lengthPadding(data, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return data[:len(data)-n], nil
+}
+
+type iso7816 struct{}
+
+func (iso7816) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 {
+		return nil, ErrBadBlockSize
+	}
+	result := append([]byte{}, data...)
+	result = append(result, 0x80)
+	return append(result, make([]byte, padLength(data, blockSize)-1)...), nil
+}
+
+// Strips the zeros from the end of the last block, then the 0x80 marker
+func (iso7816) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkPadded(data, blockSize); err != nil {
+		return nil, err
+	}
+	for i := len(data) - 1; i >= len(data)-blockSize; i-- {
+		switch data[i] {
+		case 0x00:
+			continue
+		case 0x80:
+			return data[:i], nil
+		}
+		break
+	}
+	return nil, ErrBadPadding
+}
+
+type zeroPadding struct{}
+
+func (zeroPadding) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 {
+		return nil, ErrBadBlockSize
+	}
+	result := append([]byte{}, data...)
+	if len(data)%blockSize != 0 {
+		result = append(result, make([]byte, padLength(data, blockSize))...)
+	}
+	return result, nil
+}
+
+// Strips the zeros from the end of the last block. A whole block of zeros
+// can't be padding, since Pad never adds one.
+func (zeroPadding) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize < 1 {
+		return nil, ErrBadBlockSize
+	}
+	if len(data)%blockSize != 0 {
+		return nil, errors.New("Padded data must be a multiple of the block size")
+	}
+	end := len(data)
+	for end > 0 && end > len(data)-blockSize+1 && data[end-1] == 0 {
+		end--
+	}
+	return data[:end], nil
+}
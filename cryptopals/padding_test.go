@@ -0,0 +1,171 @@
+package cryptopals
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPaddingPad(t *testing.T) {
+	for _, tt := range []struct {
+		padding  Padding
+		input    string
+		expected string
+	}{
+		{PKCS7, "abcde", "abcde\x03\x03\x03"},
+		{PKCS7, "abcdefgh", "abcdefgh\x08\x08\x08\x08\x08\x08\x08\x08"},
+		{X923, "abcde", "abcde\x00\x00\x03"},
+		{X923, "abcdefg", "abcdefg\x01"},
+		{ISO7816, "abcde", "abcde\x80\x00\x00"},
+		{ISO7816, "abcdefgh", "abcdefgh\x80\x00\x00\x00\x00\x00\x00\x00"},
+		{ZeroPadding, "abcde", "abcde\x00\x00\x00"},
+		{ZeroPadding, "abcdefgh", "abcdefgh"},
+	} {
+		result, err := tt.padding.Pad([]byte(tt.input), 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(result, []byte(tt.expected)) {
+			t.Errorf("%T: expected %q, got %q", tt.padding, tt.expected, result)
+		}
+	}
+}
+
+func TestPaddingISO10126(t *testing.T) {
+	result, err := ISO10126.Pad([]byte("abcde"), 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 8 || !bytes.Equal(result[:5], []byte("abcde")) || result[7] != 3 {
+		t.Errorf("Bad padding %q", result)
+	}
+}
+
+func TestPaddingRoundTrip(t *testing.T) {
+	for _, padding := range []Padding{PKCS7, X923, ISO10126, ISO7816, ZeroPadding} {
+		for n := 0; n <= 33; n++ {
+			input := bytes.Repeat([]byte("A"), n)
+			padded, err := padding.Pad(input, 16)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(padded)%16 != 0 {
+				t.Errorf("%T: padded length %d isn't a multiple of the block size", padding, len(padded))
+			}
+			unpadded, err := padding.Unpad(padded, 16)
+			if err != nil {
+				t.Errorf("%T: %s", padding, err)
+			}
+			if !bytes.Equal(unpadded, input) {
+				t.Errorf("%T: expected %q, got %q", padding, input, unpadded)
+			}
+		}
+	}
+}
+
+func TestPaddingUnpadErrors(t *testing.T) {
+	for _, tt := range []struct {
+		padding Padding
+		input   string
+	}{
+		{PKCS7, "abcdef\x03\x03"},
+		{PKCS7, "abcdefg\x00"},
+		{PKCS7, "abcdefg\x09"},
+		{X923, "abcde\x01\x00\x03"},
+		{X923, "abcdefg\x00"},
+		{ISO10126, "abcdefg\x09"},
+		{ISO7816, "abcdefg\x00"},
+		{ISO7816, "abcde\x80\x01\x00"},
+		{ISO7816, "\x00\x00\x00\x00\x00\x00\x00\x00"},
+		{PKCS7, "abc\x01"},
+		{ZeroPadding, "abc"},
+	} {
+		if _, err := tt.padding.Unpad([]byte(tt.input), 8); err == nil {
+			t.Errorf("%T: unpadded %q without an error", tt.padding, tt.input)
+		}
+	}
+}
+
+func TestPaddingBadBlockSize(t *testing.T) {
+	for _, padding := range []Padding{PKCS7, X923, ISO10126} {
+		if _, err := padding.Pad([]byte("zomg"), 256); err != ErrBadBlockSize {
+			t.Errorf("%T: expected ErrBadBlockSize, got %v", padding, err)
+		}
+	}
+	for _, padding := range []Padding{PKCS7, X923, ISO10126, ISO7816, ZeroPadding} {
+		if _, err := padding.Pad([]byte("zomg"), 0); err != ErrBadBlockSize {
+			t.Errorf("%T: expected ErrBadBlockSize, got %v", padding, err)
+		}
+	}
+}
+
+func TestEncryptAESCBCWithPadding(t *testing.T) {
+	key, iv := []byte("YELLOW SUBMARINE"), make([]byte, 16)
+	plaintext := []byte("I'm back and I'm ringin' the bell")
+
+	encrypted, err := EncryptAESCBCWithPadding(plaintext, key, iv, X923)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := DecryptAESCBCWithPadding(encrypted, key, iv, X923)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, decrypted)
+	}
+
+	// Same data with PKCS#7 matches the original helper
+	expected, _ := EncryptAESCBC(plaintext, key, iv)
+	encrypted, _ = EncryptAESCBCWithPadding(plaintext, key, iv, PKCS7)
+	if !bytes.Equal(encrypted, expected) {
+		t.Errorf("Expected %x, got %x", expected, encrypted)
+	}
+}
+
+func TestEncryptAESECBWithPadding(t *testing.T) {
+	key := []byte("YELLOW SUBMARINE")
+	plaintext := []byte("ECB is still a bad idea")
+
+	encrypted, err := EncryptAESECBWithPadding(plaintext, key, ISO7816)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := DecryptAESECB(encrypted, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if unpadded, err := ISO7816.Unpad(decrypted, 16); err != nil || !bytes.Equal(unpadded, plaintext) {
+		t.Errorf("Expected %q, got %q (%v)", plaintext, unpadded, err)
+	}
+}
+
+// The padding depends on the block size, not the key size
+func TestEncryptAESLongKeys(t *testing.T) {
+	iv := make([]byte, 16)
+	plaintext := []byte("Sixteen bytes!!!")
+
+	for _, key := range [][]byte{
+		[]byte("YELLOW SUBMARINE YELLOW "),
+		[]byte("YELLOW SUBMARINE YELLOW SUBMARIN"),
+	} {
+		encrypted, err := EncryptAESCBC(plaintext, key, iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(encrypted) != 32 {
+			t.Errorf("%d byte key: expected 32 bytes of CBC ciphertext, got %d", len(key), len(encrypted))
+		}
+		decrypted, err := DecryptAESCBCWithPadding(encrypted, key, iv, PKCS7)
+		if err != nil || !bytes.Equal(decrypted, plaintext) {
+			t.Errorf("%d byte key: expected %q, got %q (%v)", len(key), plaintext, decrypted, err)
+		}
+
+		encrypted, err = EncryptAESECB(plaintext, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(encrypted) != 32 {
+			t.Errorf("%d byte key: expected 32 bytes of ECB ciphertext, got %d", len(key), len(encrypted))
+		}
+	}
+}
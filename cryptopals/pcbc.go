@@ -0,0 +1,91 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+)
+
+// Propagating cipher block chaining. Like CBC, but each block is XORed with
+// both the previous plaintext and ciphertext blocks before it's encrypted:
+//
+//	C_i = E(P_i ^ P_i-1 ^ C_i-1)
+//
+// with the IV standing in for P_0 ^ C_0. Any change to the ciphertext garbles
+// everything after it.
+
+type pcbc struct {
+	b         cipher.Block
+	blockSize int
+	// P_i-1 ^ C_i-1
+	iv []byte
+}
+
+type pcbcDecrypter pcbc
+type pcbcEncrypter pcbc
+
+func newPCBC(b cipher.Block, iv []byte) *pcbc {
+	return &pcbc{
+		b:         b,
+		blockSize: b.BlockSize(),
+		iv:        append([]byte{}, iv...),
+	}
+}
+
+func NewPCBCDecrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return (*pcbcDecrypter)(newPCBC(block, iv))
+}
+
+func NewPCBCEncrypter(block cipher.Block, iv []byte) cipher.BlockMode {
+	return (*pcbcEncrypter)(newPCBC(block, iv))
+}
+
+func (x *pcbcDecrypter) BlockSize() int { return x.blockSize }
+
+func (x *pcbcEncrypter) BlockSize() int { return x.blockSize }
+
+func (x *pcbcDecrypter) CryptBlocks(dst, src []byte) {
+	if len(src)%x.blockSize != 0 {
+		panic("crypto/cipher: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	ciphertext := make([]byte, x.blockSize)
+	for len(src) > 0 {
+		copy(ciphertext, src[:x.blockSize])
+		x.b.Decrypt(dst, ciphertext)
+		if err := FixedXOR(dst[:x.blockSize], x.iv); err != nil {
+			panic(err)
+		}
+
+		copy(x.iv, dst[:x.blockSize])
+		if err := FixedXOR(x.iv, ciphertext); err != nil {
+			panic(err)
+		}
+		src = src[x.blockSize:]
+		dst = dst[x.blockSize:]
+	}
+}
+
+func (x *pcbcEncrypter) CryptBlocks(dst, src []byte) {
+	if len(src)%x.blockSize != 0 {
+		panic("crypto/cipher: input not full blocks")
+	}
+	if len(dst) < len(src) {
+		panic("crypto/cipher: output smaller than input")
+	}
+	plaintext := make([]byte, x.blockSize)
+	for len(src) > 0 {
+		copy(plaintext, src[:x.blockSize])
+		if err := FixedXOR(x.iv, plaintext); err != nil {
+			panic(err)
+		}
+		x.b.Encrypt(dst, x.iv)
+
+		copy(x.iv, dst[:x.blockSize])
+		if err := FixedXOR(x.iv, plaintext); err != nil {
+			panic(err)
+		}
+		src = src[x.blockSize:]
+		dst = dst[x.blockSize:]
+	}
+}
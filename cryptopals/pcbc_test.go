@@ -0,0 +1,67 @@
+package cryptopals
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAESPCBCEncryptDecrypt(t *testing.T) {
+	key, iv := randomBytes(t, 16), randomBytes(t, 16)
+	plaintext := []byte("this is some plaintext which spans a few blocks")
+
+	encrypted, err := EncryptAESPCBC(plaintext, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decrypted, err := DecryptAESPCBC(encrypted, key, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(MaybePKCS7Unpad(decrypted), plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, decrypted)
+	}
+
+	// The first block is the same as CBC's, since P_0 ^ C_0 is the IV
+	cbc, _ := EncryptAESCBC(plaintext, key, iv)
+	if !bytes.Equal(encrypted[:16], cbc[:16]) || bytes.Equal(encrypted[16:32], cbc[16:32]) {
+		t.Error("Only the first block should match CBC")
+	}
+}
+
+func TestAESPCBCBadInput(t *testing.T) {
+	key := randomBytes(t, 16)
+	if _, err := EncryptAESPCBC([]byte("data"), key, randomBytes(t, 8)); err == nil {
+		t.Error("Expected an error for a short IV")
+	}
+	if _, err := DecryptAESPCBC(randomBytes(t, 20), key, randomBytes(t, 16)); err == nil {
+		t.Error("Expected an error for a partial block")
+	}
+
+	// Padding mustn't write into the spare capacity of the caller's slice
+	data := make([]byte, 4, 32)
+	spare := data[:32]
+	if _, err := EncryptAESPCBC(data, key, randomBytes(t, 16)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(spare[4:], make([]byte, 28)) {
+		t.Errorf("Padding was written past the end of the input: %v", spare)
+	}
+}
+
+func TestPCBCPropagatesErrors(t *testing.T) {
+	key, iv := randomBytes(t, 16), randomBytes(t, 16)
+	plaintext := bytes.Repeat([]byte("A"), 64)
+
+	encrypted, _ := EncryptAESPCBC(plaintext, key, iv)
+	encrypted[20] ^= 0x01
+	decrypted, _ := DecryptAESPCBC(encrypted, key, iv)
+
+	if !bytes.Equal(decrypted[:16], plaintext[:16]) {
+		t.Error("The block before the change was garbled")
+	}
+	for i := 16; i < len(plaintext); i += 16 {
+		if bytes.Equal(decrypted[i:i+16], plaintext[i:i+16]) {
+			t.Errorf("Block %d survived the change", i/16)
+		}
+	}
+}
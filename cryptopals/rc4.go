@@ -0,0 +1,70 @@
+package cryptopals
+
+import (
+	"crypto/cipher"
+	"fmt"
+)
+
+// The RC4 stream cipher. Go has one of these too, but it's deprecated and we
+// want to see how the keystream is generated.
+
+type rc4 struct {
+	s    [256]uint8
+	i, j uint8
+}
+
+var _ cipher.Stream = (*rc4)(nil)
+
+// Creates an RC4 cipher with a key of 1 to 256 bytes, using the key schedule
+// to permute the state
+func NewRC4(key []byte) (cipher.Stream, error) {
+	if len(key) < 1 || len(key) > 256 {
+		return nil, fmt.Errorf("Invalid RC4 key size %d", len(key))
+	}
+
+	c := &rc4{}
+	for i := range c.s {
+		c.s[i] = uint8(i)
+	}
+
+	var j uint8
+	k := 0
+	for i := 0; i < 256; i++ {
+		j += c.s[uint8(i)] + key[k]
+		c.s[uint8(i)], c.s[j] = c.s[j], c.s[uint8(i)]
+		if k++; k == len(key) {
+			k = 0
+		}
+	}
+
+	return c, nil
+}
+
+// XORs each byte of `src` with the next byte of the keystream and writes the
+// result to `dst`
+func (c *rc4) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("rc4: output smaller than input")
+	}
+
+	i, j := c.i, c.j
+	for k, v := range src {
+		i++
+		j += c.s[i]
+		c.s[i], c.s[j] = c.s[j], c.s[i]
+		dst[k] = v ^ c.s[c.s[i]+c.s[j]]
+	}
+	c.i, c.j = i, j
+}
+
+// Encrypts or decrypts `data` with RC4 under `key`
+func RC4(data, key []byte) ([]byte, error) {
+	c, err := NewRC4(key)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	result := make([]byte, len(data))
+	c.XORKeyStream(result, data)
+	return result, nil
+}
@@ -0,0 +1,52 @@
+package cryptopals
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+func TestRC4(t *testing.T) {
+	// Test vectors from Wikipedia
+	for _, tt := range []struct {
+		key, plaintext, expected string
+	}{
+		{"Key", "Plaintext", "bbf316e8d940af0ad3"},
+		{"Wiki", "pedia", "1021bf0420"},
+		{"Secret", "Attack at dawn", "45a01f645fc35b383552544b9bf5"},
+	} {
+		result, err := RC4([]byte(tt.plaintext), []byte(tt.key))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(result) != tt.expected {
+			t.Errorf("Incorrect ciphertext for key %q.\nExpected:\t%s\nGot:\t\t%x", tt.key, tt.expected, result)
+		}
+	}
+}
+
+func TestRC4Stream(t *testing.T) {
+	// Encrypting in pieces should continue the same keystream
+	plaintext := []byte("Attack at dawn")
+	expected, _ := RC4(plaintext, []byte("Secret"))
+
+	c, err := NewRC4([]byte("Secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := make([]byte, len(plaintext))
+	c.XORKeyStream(result[:5], plaintext[:5])
+	c.XORKeyStream(result[5:], plaintext[5:])
+
+	if hex.EncodeToString(result) != hex.EncodeToString(expected) {
+		t.Errorf("Expected %x, got %x", expected, result)
+	}
+}
+
+func TestRC4KeySize(t *testing.T) {
+	if _, err := NewRC4([]byte{}); err == nil {
+		t.Error("Expected an error for an empty key")
+	}
+	if _, err := NewRC4(make([]byte, 257)); err == nil {
+		t.Error("Expected an error for a 257 byte key")
+	}
+}
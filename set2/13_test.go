@@ -43,7 +43,7 @@ func TestEncryptDecryptUser(t *testing.T) {
 	user2 := DecryptNewUser(encrypted, key)
 
 	if user1.Uid != user2.Uid {
-		t.Errorf("Decrypted UID does not match: %s", user2.Uid)
+		t.Errorf("Decrypted UID does not match: %d", user2.Uid)
 	}
 
 	if user1.Email != user2.Email {
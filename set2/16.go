@@ -46,6 +46,7 @@
 package set_two
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -59,10 +60,18 @@ func sanitizeInput(input string) string {
 	return strings.Replace(strings.Replace(input, ";", "\";\"", -1), "=", "\"=\"", -1)
 }
 
+// Encrypts or decrypts with a mode of operation, like cryptopals.EncryptAESCBC
+type CryptFunc func(data, key, iv []byte) ([]byte, error)
+
 func EncryptedComment(input string) []byte {
+	return EncryptedCommentWith(input, cryptopals.EncryptAESCBC, iv)
+}
+
+// Encrypts the comment with any mode, so we can compare how they hold up
+func EncryptedCommentWith(input string, encrypt CryptFunc, iv []byte) []byte {
 	input = sanitizeInput(input)
 	plaintext := fmt.Sprintf("comment1=cooking%%20MCs;userdata=%s;comment2=%%20like%%20a%%20pound%%20of%%20bacon", input)
-	encrypted, err := cryptopals.EncryptAESCBC([]byte(plaintext), cryptopals.RANDOM_KEY, iv)
+	encrypted, err := encrypt([]byte(plaintext), cryptopals.RANDOM_KEY, iv)
 	if err != nil {
 		panic(err)
 	}
@@ -70,8 +79,12 @@ func EncryptedComment(input string) []byte {
 }
 
 func DecryptCommentAndCheckAdmin(input []byte) (bool, error) {
+	return DecryptCommentAndCheckAdminWith(input, cryptopals.DecryptAESCBC, iv)
+}
+
+func DecryptCommentAndCheckAdminWith(input []byte, decrypt CryptFunc, iv []byte) (bool, error) {
 	adminString := ";admin=true;"
-	decrypted, err := cryptopals.DecryptAESCBC(input, cryptopals.RANDOM_KEY, iv)
+	decrypted, err := decrypt(input, cryptopals.RANDOM_KEY, iv)
 	if err != nil {
 		return false, err
 	}
@@ -106,20 +119,36 @@ func DecryptCommentAndCheckAdmin(input []byte) (bool, error) {
 // C1' ^ E2 = ";admin=true;lol="
 func BitflipInjectAdmin(ciphertext []byte) ([]byte, error) {
 	blockSize := 16
-	inject := []byte(";admin=true;lol=")
-	secondBlock := []byte("%20MCs;userdata=")
+	return BitflipInject(ciphertext, blockSize, []byte("%20MCs;userdata="), []byte(";admin=true;lol="), blockSize)
+}
 
-	// XOR the plaintext of the second block with the ciphertext of the first
-	// block to determine the encrypted value without the CBC XOR
-	if err := cryptopals.FixedXOR(secondBlock, ciphertext[:blockSize]); err != nil {
-		return []byte{}, err
+// Flips bits in the ciphertext so that `known` plaintext at `offset` decrypts
+// to `inject` instead, by XORing known ^ inject into the ciphertext `shift`
+// bytes before it.
+//
+// How far back to flip depends on the mode. For CBC it's a whole block,
+// which garbles the block we flip. For CTR, OFB and CFB it's zero, since the
+// ciphertext is XORed straight into the plaintext. OFB and CTR leave the rest
+// alone and CFB garbles the next block. CFB-8 garbles the 16 bytes after
+// every byte we flip, so we only get to set one byte at a time. PCBC and IGE
+// feed each plaintext block into the next, so there's nowhere to flip that
+// doesn't garble what we inject.
+func BitflipInject(ciphertext []byte, offset int, known, inject []byte, shift int) ([]byte, error) {
+	if len(known) != len(inject) {
+		return []byte{}, errors.New("Known and injected plaintext must be the same length")
+	}
+	start := offset - shift
+	if start < 0 || start+len(inject) > len(ciphertext) {
+		return []byte{}, errors.New("Injection is out of range of the ciphertext")
 	}
 
-	// XOR the encrypted block with the injected admin block to find out what we
-	// should set our ciphertext block to.
-	if err := cryptopals.FixedXOR(inject, secondBlock); err != nil {
+	result := append([]byte{}, ciphertext...)
+	flip := append([]byte{}, known...)
+	if err := cryptopals.FixedXOR(flip, inject); err != nil {
 		return []byte{}, err
 	}
-
-	return append(inject, ciphertext[blockSize:]...), nil
+	if err := cryptopals.FixedXOR(result[start:start+len(flip)], flip); err != nil {
+		return []byte{}, err
+	}
+	return result, nil
 }
@@ -2,6 +2,8 @@ package set_two
 
 import (
 	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
 )
 
 func TestEncryptedComment(t *testing.T) {
@@ -46,3 +48,51 @@ func TestBitflipInjectAdmin(t *testing.T) {
 		t.Errorf("Bitflip injection failed")
 	}
 }
+
+func TestBitflipInjectAcrossModes(t *testing.T) {
+	blockSize := 16
+	known, inject := []byte("%20MCs;userdata="), []byte(";admin=true;lol=")
+	iv32 := append(append([]byte{}, iv...), iv...)
+
+	for _, mode := range []struct {
+		name             string
+		encrypt, decrypt CryptFunc
+		iv               []byte
+		shift            int
+		malleable        bool
+	}{
+		{"CBC", cryptopals.EncryptAESCBC, cryptopals.DecryptAESCBC, iv, blockSize, true},
+		{"CFB", cryptopals.EncryptAESCFB, cryptopals.DecryptAESCFB, iv, 0, true},
+		// Each flipped byte garbles the keystream for the next 16, including
+		// the rest of what we inject
+		{"CFB-8", cryptopals.EncryptAESCFB8, cryptopals.DecryptAESCFB8, iv, 0, false},
+		{"OFB", cryptopals.EncryptAESOFB, cryptopals.DecryptAESOFB, iv, 0, true},
+		{"PCBC", cryptopals.EncryptAESPCBC, cryptopals.DecryptAESPCBC, iv, blockSize, false},
+		{"PCBC in place", cryptopals.EncryptAESPCBC, cryptopals.DecryptAESPCBC, iv, 0, false},
+		{"IGE", cryptopals.EncryptAESIGE, cryptopals.DecryptAESIGE, iv32, blockSize, false},
+		{"IGE in place", cryptopals.EncryptAESIGE, cryptopals.DecryptAESIGE, iv32, 0, false},
+	} {
+		ciphertext := EncryptedCommentWith("ohai", mode.encrypt, mode.iv)
+		result, err := BitflipInject(ciphertext, blockSize, known, inject, mode.shift)
+		if err != nil {
+			t.Fatal(err)
+		}
+		isAdmin, err := DecryptCommentAndCheckAdminWith(result, mode.decrypt, mode.iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isAdmin != mode.malleable {
+			t.Errorf("%s: expected admin to be %v", mode.name, mode.malleable)
+		}
+	}
+}
+
+func TestBitflipInjectOutOfRange(t *testing.T) {
+	ciphertext := EncryptedComment("ohai")
+	if _, err := BitflipInject(ciphertext, 0, []byte("a"), []byte("b"), 16); err == nil {
+		t.Error("Flipped bits before the start of the ciphertext")
+	}
+	if _, err := BitflipInject(ciphertext, 0, []byte("ab"), []byte("b"), 0); err == nil {
+		t.Error("Accepted mismatched lengths")
+	}
+}
@@ -63,7 +63,7 @@
 package set_three
 
 import (
-	"bytes"
+	"crypto/aes"
 
 	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
 )
@@ -73,6 +73,11 @@ type PaddingOracle func([]byte) bool
 var iv = []byte("YELLOW SUBMARINE")
 
 func EncryptRandomString() ([]byte, []byte) {
+	return EncryptRandomStringWithPadding(cryptopals.PKCS7)
+}
+
+// Like EncryptRandomString, but for servers which don't pad with PKCS#7
+func EncryptRandomStringWithPadding(padding cryptopals.Padding) ([]byte, []byte) {
 	possibilities := []string{
 		"MDAwMDAwTm93IHRoYXQgdGhlIHBhcnR5IGlzIGp1bXBpbmc=",
 		"MDAwMDAxV2l0aCB0aGUgYmFzcyBraWNrZWQgaW4gYW5kIHRoZSBWZWdhJ3MgYXJlIHB1bXBpbic=",
@@ -87,7 +92,7 @@ func EncryptRandomString() ([]byte, []byte) {
 	}
 
 	i := cryptopals.RandomInt(0, len(possibilities)-1)
-	encrypted, err := cryptopals.EncryptAESCBC([]byte(possibilities[i]), cryptopals.RANDOM_KEY, iv)
+	encrypted, err := cryptopals.EncryptAESCBCWithPadding([]byte(possibilities[i]), cryptopals.RANDOM_KEY, iv, padding)
 	if err != nil {
 		panic(err)
 	}
@@ -102,24 +107,12 @@ func CBCPaddingOracle(ciphertext []byte) bool {
 	return cryptopals.IsPKCS7Padded(decrypted)
 }
 
-// Create a byte slice which when XORed against i2, produces
-// padLength bytes at the end of the result which are one byte
-// short of making a full PKCS7 pad.
-// This result is then used in C1' to brute force the next byte
-func generateInjectionPad(i2 []byte, padLength int) []byte {
-	blockSize := len(i2)
-
-	if padLength < 1 {
-		panic("generateInjectionPad: invalid padLength")
+// A padding oracle for a server which expects `padding` instead of PKCS#7
+func NewCBCPaddingOracle(padding cryptopals.Padding) PaddingOracle {
+	return func(ciphertext []byte) bool {
+		_, err := cryptopals.DecryptAESCBCWithPadding(ciphertext, cryptopals.RANDOM_KEY, iv, padding)
+		return err == nil
 	}
-
-	randomPrefix, _ := cryptopals.GenerateRandomBytes(blockSize - padLength)
-
-	// Generate slice of padded values XORed with the intermediate block for C1'
-	pad := bytes.Repeat([]byte{byte(padLength + 1)}, padLength)
-	cryptopals.FixedXOR(pad, i2[len(i2)-padLength:])
-
-	return append(randomPrefix, pad...)
 }
 
 /* Brute force the plaintext of C2 by exploiting the padding oracle
@@ -150,54 +143,47 @@ func generateInjectionPad(i2 []byte, padLength int) []byte {
  *     correct padding value.
  *
  *         C1'[15] = I2[15] ^ 0x02
+ *
+ *  PaddingOracleAttack does the work, and checks each hit so a plaintext
+ *  ending in 0x02 0x02 can't pass for 0x01.
  */
 func BruteForceBlock(c1, c2 []byte, oracle PaddingOracle) []byte {
 	if len(c1) != len(c2) {
 		panic("Block lengths do not match")
 	}
-	blockSize := len(c2)
-
-	// This is the block which we will be injecting as C1'
-	inject, _ := cryptopals.GenerateRandomBytes(blockSize)
-
-	// The intermediate block which is XORed with the decrypted text
-	i2 := make([]byte, blockSize)
-
-	// The result
-	plaintext := make([]byte, blockSize)
-
-	for i := blockSize - 1; i >= 0; i-- {
-		padLength := blockSize - (i % blockSize)
-		// Generate values for inject[i] until we find a valid pad
-		for j := 0; j < 256; j++ {
-			inject[i] = byte(j)
-			if oracle(append(inject, c2...)) {
-				// XOR the injected byte with the correct pad value (length)
-				// to determine the intermediate value
-				i2[i] = byte(j ^ padLength)
-				// Now that we know the intermediate value and the
-				// ciphertext of the preceding block, we can determine
-				// the plaintext value
-				plaintext[i] = i2[i] ^ c1[i]
-				inject = generateInjectionPad(i2, padLength)
-
-				break
-			}
-		}
+	attack, err := NewPaddingOracleAttack(oracle, len(c2), cryptopals.PKCS7)
+	if err != nil {
+		panic(err)
+	}
+	plaintext, err := attack.DecryptBlock(c1, c2)
+	if err != nil {
+		panic(err)
 	}
-
 	return plaintext
 }
 
 // We can use the padding oracle and some intercepted ciphertext to manipulate
 // inputs of blocks until they yield padded plaintext.
+// See PaddingOracleAttack for the engine, which handles other block sizes
+// and padding schemes too.
 func BruteForcePaddingOracle(ciphertext, iv []byte, oracle PaddingOracle) []byte {
-	var result []byte
-	blockSize := 16
-	ciphertextWithIV := append(iv, ciphertext...)
-	blocks := cryptopals.SplitBytes(ciphertextWithIV, blockSize)
-	for i := 1; i < len(blocks); i++ {
-		result = append(result, BruteForceBlock(blocks[i-1], blocks[i], oracle)...)
+	attack, err := NewPaddingOracleAttack(oracle, len(iv), cryptopals.PKCS7)
+	if err != nil {
+		panic(err)
+	}
+	result, err := attack.Decrypt(ciphertext, iv)
+	if err != nil {
+		panic(err)
 	}
 	return result
 }
+
+// Runs the padding oracle attack in reverse to forge an IV and ciphertext
+// which decrypt to `plaintext`, without ever knowing the key.
+func ForgeCiphertext(plaintext []byte, oracle PaddingOracle) ([]byte, []byte, error) {
+	attack, err := NewPaddingOracleAttack(oracle, aes.BlockSize, cryptopals.PKCS7)
+	if err != nil {
+		return nil, nil, err
+	}
+	return attack.Encrypt(plaintext)
+}
@@ -1,6 +1,7 @@
 package set_three
 
 import (
+	"bytes"
 	"testing"
 
 	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
@@ -19,6 +20,29 @@ func TestCBCPaddingOracle(t *testing.T) {
 	}
 }
 
+func TestNewCBCPaddingOracle(t *testing.T) {
+	for _, padding := range []cryptopals.Padding{
+		cryptopals.PKCS7,
+		cryptopals.X923,
+		cryptopals.ISO10126,
+		cryptopals.ISO7816,
+	} {
+		oracle := NewCBCPaddingOracle(padding)
+		ciphertext, _ := EncryptRandomStringWithPadding(padding)
+		if !oracle(ciphertext) {
+			t.Errorf("%T oracle rejected a valid ciphertext", padding)
+		}
+
+		// Garbling the second to last block garbles the padding too
+		ciphertext[len(ciphertext)-17] ^= 0x01
+		ciphertext[len(ciphertext)-18] ^= 0x55
+		ciphertext[len(ciphertext)-32] ^= 0x55
+		if padding != cryptopals.ISO10126 && oracle(ciphertext) {
+			t.Errorf("%T oracle accepted a garbled ciphertext", padding)
+		}
+	}
+}
+
 func TestBruteForcePaddingOracle(t *testing.T) {
 	for i := 0; i < 10; i++ {
 		ciphertext, iv := EncryptRandomString()
@@ -28,26 +52,41 @@ func TestBruteForcePaddingOracle(t *testing.T) {
 		if err != nil {
 			t.Errorf("Base 64 decoding failed: %s. Input: %s", err, base64Result)
 		}
-		t.Logf(result)
+		t.Log(result)
 	}
 }
 
-func TestGenerateInjectionPad(t *testing.T) {
-	i2, err := cryptopals.GenerateRandomBytes(16)
+func TestBruteForceBlock(t *testing.T) {
+	ciphertext, iv := EncryptRandomString()
+	decrypted, err := cryptopals.DecryptAESCBC(ciphertext, cryptopals.RANDOM_KEY, iv)
 	if err != nil {
-		t.Errorf("Error: %s", err)
-	}
-	// Test pad creation for all pad lengths
-	for i := 1; i < len(i2); i++ {
-		result := generateInjectionPad(i2, i)
-		correctPad := byte(i + 1)
-
-		// Check the last i bytes of our result to make sure that
-		//   i2[len(i2)-j] ^ result[len(result)-j] == correctPad
-		for j := 1; j <= i; j++ {
-			if pad := (i2[len(i2)-j] ^ result[len(result)-j]); pad != correctPad {
-				t.Errorf("Invalid injection pad.\n  c1': %v\n  i2: %v\n  padLength: %d", result, i2, i)
-			}
-		}
+		t.Fatal(err)
+	}
+
+	result := BruteForceBlock(ciphertext[:16], ciphertext[16:32], CBCPaddingOracle)
+	if !bytes.Equal(result, decrypted[16:32]) {
+		t.Errorf("Expected %q, got %q", decrypted[16:32], result)
+	}
+}
+
+func TestForgeCiphertext(t *testing.T) {
+	plaintext := []byte("Padding oracles decrypt, but they can encrypt too")
+
+	forgedIV, ciphertext, err := ForgeCiphertext(plaintext, CBCPaddingOracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The oracle's server decrypts it with its own key
+	decrypted, err := cryptopals.DecryptAESCBC(ciphertext, cryptopals.RANDOM_KEY, forgedIV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := cryptopals.PKCS7Unpad(decrypted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(result) != string(plaintext) {
+		t.Errorf("Expected %q, got %q", plaintext, result)
 	}
 }
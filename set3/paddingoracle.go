@@ -0,0 +1,272 @@
+package set_three
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+// How many guesses we send to the oracle at once by default
+const defaultPaddingOracleWorkers = 16
+
+// A CBC padding oracle attack which works for any padding scheme where we can
+// build up a valid pad one byte at a time, like PKCS#7, ANSI X.923 and
+// ISO/IEC 7816-4.
+//
+// For each byte of a block, from the end, we set the bytes we already know
+// to the tail of a valid pad, and try every value of the next byte until the
+// oracle accepts. The oracle is called from several goroutines at once, so it
+// must be safe for concurrent use.
+type PaddingOracleAttack struct {
+	oracle    PaddingOracle
+	blockSize int
+	padding   cryptopals.Padding
+	// targets[n-1] is a valid pad of exactly n bytes
+	targets [][]byte
+	queries int64
+
+	// The number of guesses to try concurrently
+	Workers int
+	// If set, called after each block is decrypted with the number of
+	// blocks done, the total and the oracle queries so far
+	Progress func(done, total int, queries int64)
+}
+
+// Works out the pads of each length for a padding scheme, and makes sure the
+// first byte of each pad is the only value which makes it valid. Schemes with
+// random or unchecked pad bytes, like ISO 10126 and zero padding, don't
+// leak anything byte by byte.
+func padTargets(padding cryptopals.Padding, blockSize int) ([][]byte, error) {
+	targets := make([][]byte, blockSize)
+	for n := 1; n <= blockSize; n++ {
+		prefix := bytes.Repeat([]byte("A"), blockSize-n)
+		padded, err := padding.Pad(prefix, blockSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(padded) < blockSize {
+			return nil, fmt.Errorf("%T didn't pad out a full block", padding)
+		}
+		block := padded[:blockSize]
+		if _, err := padding.Unpad(block, blockSize); err != nil {
+			return nil, err
+		}
+
+		block[blockSize-n] ^= 0x01
+		if _, err := padding.Unpad(block, blockSize); err == nil {
+			return nil, fmt.Errorf("A padding oracle for %T can't recover plaintext byte by byte", padding)
+		}
+		block[blockSize-n] ^= 0x01
+
+		targets[n-1] = block[blockSize-n:]
+	}
+	return targets, nil
+}
+
+func NewPaddingOracleAttack(oracle PaddingOracle, blockSize int, padding cryptopals.Padding) (*PaddingOracleAttack, error) {
+	if blockSize < 2 || blockSize > 255 {
+		return nil, errors.New("Invalid block size")
+	}
+	targets, err := padTargets(padding, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	return &PaddingOracleAttack{
+		oracle:    oracle,
+		blockSize: blockSize,
+		padding:   padding,
+		targets:   targets,
+		Workers:   defaultPaddingOracleWorkers,
+	}, nil
+}
+
+// The number of times we've called the oracle
+func (a *PaddingOracleAttack) Queries() int64 {
+	return atomic.LoadInt64(&a.queries)
+}
+
+func (a *PaddingOracleAttack) query(inject, c []byte) bool {
+	atomic.AddInt64(&a.queries, 1)
+	return a.oracle(append(append([]byte{}, inject...), c...))
+}
+
+// Tries `guess` as byte i of the injected block. If the oracle accepts, we
+// change the byte before it and ask again. The real pad doesn't depend on
+// that byte, but a false positive from a longer pad does: for PKCS#7, a
+// plaintext ending in 0x02 0x02 is valid when we hit 0x02 instead of 0x01.
+func (a *PaddingOracleAttack) tryGuess(inject, c []byte, i int, guess byte) bool {
+	block := append([]byte{}, inject...)
+	block[i] = guess
+	if !a.query(block, c) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	block[i-1] ^= 0x01
+	return a.query(block, c)
+}
+
+// Tries every value of byte i of the injected block across the workers, and
+// returns the first one that gives valid padding
+func (a *PaddingOracleAttack) guessByte(inject, c []byte, i int) (byte, error) {
+	workers := a.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	guesses := make(chan byte)
+	found := make(chan byte, 256)
+	done := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for guess := range guesses {
+				if a.tryGuess(inject, c, i, guess) {
+					found <- guess
+				}
+			}
+		}()
+	}
+
+	go func() {
+		defer close(guesses)
+		for guess := 0; guess < 256; guess++ {
+			select {
+			case guesses <- byte(guess):
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(found)
+	}()
+
+	guess, ok := <-found
+	close(done)
+	// Wait for the stragglers so nobody is still reading inject when we
+	// return
+	for range found {
+	}
+
+	if !ok {
+		return 0, fmt.Errorf("No value of byte %d gave valid padding", i)
+	}
+	return guess, nil
+}
+
+// Recovers the intermediate state of a ciphertext block: its decryption
+// before it's XORed with the previous block
+func (a *PaddingOracleAttack) Intermediate(c []byte) ([]byte, error) {
+	if len(c) != a.blockSize {
+		return nil, errors.New("Ciphertext block is the wrong size")
+	}
+
+	inject, err := cryptopals.GenerateRandomBytes(a.blockSize)
+	if err != nil {
+		return nil, err
+	}
+	intermediate := make([]byte, a.blockSize)
+
+	for i := a.blockSize - 1; i >= 0; i-- {
+		// Make the bytes we know decrypt to the tail of a pad one byte
+		// longer than them
+		target := a.targets[a.blockSize-i-1]
+		for j := i + 1; j < a.blockSize; j++ {
+			inject[j] = intermediate[j] ^ target[j-i]
+		}
+
+		guess, err := a.guessByte(inject, c, i)
+		if err != nil {
+			return nil, err
+		}
+		intermediate[i] = guess ^ target[0]
+	}
+
+	return intermediate, nil
+}
+
+// Decrypts a single block, given the ciphertext block before it
+func (a *PaddingOracleAttack) DecryptBlock(prev, c []byte) ([]byte, error) {
+	if len(prev) != a.blockSize {
+		return nil, errors.New("Ciphertext block is the wrong size")
+	}
+	intermediate, err := a.Intermediate(c)
+	if err != nil {
+		return nil, err
+	}
+	if err := cryptopals.FixedXOR(intermediate, prev); err != nil {
+		return nil, err
+	}
+	return intermediate, nil
+}
+
+// Decrypts the whole ciphertext. The result still has its padding.
+func (a *PaddingOracleAttack) Decrypt(ciphertext, iv []byte) ([]byte, error) {
+	if len(iv) != a.blockSize || len(ciphertext)%a.blockSize != 0 {
+		return nil, errors.New("Ciphertext must be a whole number of blocks")
+	}
+
+	var result []byte
+	blocks := cryptopals.SplitBytes(append(append([]byte{}, iv...), ciphertext...), a.blockSize)
+	for i := 1; i < len(blocks); i++ {
+		plaintext, err := a.DecryptBlock(blocks[i-1], blocks[i])
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, plaintext...)
+
+		if a.Progress != nil {
+			a.Progress(i, len(blocks)-1, a.Queries())
+		}
+	}
+	return result, nil
+}
+
+// Encrypts plaintext of our choosing without the key, which is CBC-R.
+//
+// Decryption XORs the intermediate state of each block with the block before
+// it, and we control that block. So we pick a random last block, recover its
+// intermediate state with the oracle, and XOR it with the plaintext we want
+// to get the block before. Then do the same for that block, all the way back
+// to the IV.
+func (a *PaddingOracleAttack) Encrypt(plaintext []byte) (iv, ciphertext []byte, err error) {
+	padded, err := a.padding.Pad(plaintext, a.blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	blocks := cryptopals.SplitBytes(padded, a.blockSize)
+
+	last, err := cryptopals.GenerateRandomBytes(a.blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	ciphertext = last
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		prev, err := a.Intermediate(ciphertext[:a.blockSize])
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := cryptopals.FixedXOR(prev, blocks[i]); err != nil {
+			return nil, nil, err
+		}
+		ciphertext = append(prev, ciphertext...)
+
+		if a.Progress != nil {
+			a.Progress(len(blocks)-i, len(blocks), a.Queries())
+		}
+	}
+
+	return ciphertext[:a.blockSize], ciphertext[a.blockSize:], nil
+}
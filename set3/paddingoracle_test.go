@@ -0,0 +1,170 @@
+package set_three
+
+import (
+	"bytes"
+	"crypto/aes"
+	"sync"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+func TestPaddingOracleAttack(t *testing.T) {
+	plaintext := []byte("Padding oracles have nothing to do with the actual padding")
+
+	for _, padding := range []cryptopals.Padding{
+		cryptopals.PKCS7,
+		cryptopals.X923,
+		cryptopals.ISO7816,
+	} {
+		ciphertext, err := cryptopals.EncryptAESCBCWithPadding(plaintext, cryptopals.RANDOM_KEY, iv, padding)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		attack, err := NewPaddingOracleAttack(NewCBCPaddingOracle(padding), aes.BlockSize, padding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var progress []int
+		attack.Progress = func(done, total int, queries int64) {
+			if total != len(ciphertext)/aes.BlockSize {
+				t.Errorf("Expected %d blocks, got %d", len(ciphertext)/aes.BlockSize, total)
+			}
+			progress = append(progress, done)
+		}
+
+		padded, err := attack.Decrypt(ciphertext, iv)
+		if err != nil {
+			t.Fatal(err)
+		}
+		result, err := padding.Unpad(padded, aes.BlockSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(result, plaintext) {
+			t.Errorf("%T: expected %q, got %q", padding, plaintext, result)
+		}
+		if len(progress) != 4 || progress[3] != 4 {
+			t.Errorf("Unexpected progress reports %v", progress)
+		}
+		if attack.Queries() == 0 {
+			t.Error("No queries were counted")
+		}
+	}
+}
+
+// The last plaintext byte decrypting to 0x02 0x02 used to fool the attack
+// into thinking it had found 0x01.
+//
+// The stub oracle decrypts with a fixed intermediate block, except that it
+// picks byte 14 when it sees the first query so the injected block makes it
+// 0x02. Byte 15 of the intermediate is 0x03, so the single worker tries the
+// false positive 0x03^0x02 before the real hit, 0x03^0x01.
+func TestPaddingOracleAttackFalsePositive(t *testing.T) {
+	intermediate := bytes.Repeat([]byte{0x42}, aes.BlockSize)
+	intermediate[15] = 0x03
+
+	var mu sync.Mutex
+	var queries, firstAccepted int
+	oracle := func(ciphertext []byte) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		queries++
+
+		inject := ciphertext[:aes.BlockSize]
+		if queries == 1 {
+			intermediate[14] = inject[14] ^ 0x02
+		}
+		plaintext := append([]byte{}, inject...)
+		cryptopals.FixedXOR(plaintext, intermediate)
+
+		_, err := cryptopals.PKCS7.Unpad(plaintext, aes.BlockSize)
+		if err == nil && firstAccepted == 0 {
+			firstAccepted = queries
+			if plaintext[14] != 0x02 || plaintext[15] != 0x02 {
+				t.Errorf("The first hit should be the false positive, got %v", plaintext)
+			}
+		}
+		return err == nil
+	}
+
+	attack, err := NewPaddingOracleAttack(oracle, aes.BlockSize, cryptopals.PKCS7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack.Workers = 1
+
+	result, err := attack.Intermediate(make([]byte, aes.BlockSize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, intermediate) {
+		t.Errorf("Expected %v, got %v", intermediate, result)
+	}
+	// The false positive is the second guess, and the query confirming it
+	// must have been rejected for the attack to carry on to the third
+	if firstAccepted != 2 {
+		t.Errorf("Expected the false positive on query 2, got %d", firstAccepted)
+	}
+}
+
+func TestPaddingOracleAttackSingleWorker(t *testing.T) {
+	ciphertext, iv := EncryptRandomString()
+	attack, err := NewPaddingOracleAttack(CBCPaddingOracle, aes.BlockSize, cryptopals.PKCS7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	attack.Workers = 1
+
+	result, err := attack.Decrypt(ciphertext, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cryptopals.PKCS7.Unpad(result, aes.BlockSize); err != nil {
+		t.Errorf("Decrypted plaintext isn't padded: %q", result)
+	}
+}
+
+func TestNewPaddingOracleAttackUnsupported(t *testing.T) {
+	for _, padding := range []cryptopals.Padding{cryptopals.ISO10126, cryptopals.ZeroPadding} {
+		if _, err := NewPaddingOracleAttack(CBCPaddingOracle, aes.BlockSize, padding); err == nil {
+			t.Errorf("%T shouldn't be attackable byte by byte", padding)
+		}
+	}
+}
+
+func TestPaddingOracleAttackEncrypt(t *testing.T) {
+	plaintext := []byte("{\"admin\": true, \"user\": \"cbc-r\"}")
+
+	for _, padding := range []cryptopals.Padding{cryptopals.X923, cryptopals.ISO7816} {
+		attack, err := NewPaddingOracleAttack(NewCBCPaddingOracle(padding), aes.BlockSize, padding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		forgedIV, ciphertext, err := attack.Encrypt(plaintext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(forgedIV) != aes.BlockSize || len(ciphertext) != 3*aes.BlockSize {
+			t.Fatalf("Unexpected lengths %d and %d", len(forgedIV), len(ciphertext))
+		}
+
+		result, err := cryptopals.DecryptAESCBCWithPadding(ciphertext, cryptopals.RANDOM_KEY, forgedIV, padding)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(result, plaintext) {
+			t.Errorf("%T: expected %q, got %q", padding, plaintext, result)
+		}
+
+		// And the decryption attack gets it back too
+		recovered, err := attack.Decrypt(ciphertext, forgedIV)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if unpadded, _ := padding.Unpad(recovered, aes.BlockSize); !bytes.Equal(unpadded, plaintext) {
+			t.Errorf("%T: decryption attack got %q", padding, recovered)
+		}
+	}
+}
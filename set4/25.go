@@ -58,17 +58,16 @@ func Edit(cipher, key []byte, offset int, newText []byte) []byte {
 		panic(err)
 	}
 
+	// Jump straight to the block at offset instead of generating the whole
+	// keystream up to it
 	ctr := cryptopals.NewCTR(block, 0)
-	keystream := ctr.KeystreamBytes(offset, len(newText))
-
-	newCipher := make([]byte, len(newText))
-	copy(newCipher, newText)
-
-	err = cryptopals.FixedXOR(newCipher, keystream)
-	if err != nil {
+	if err := ctr.Seek(uint64(offset)); err != nil {
 		panic(err)
 	}
 
+	newCipher := make([]byte, len(newText))
+	ctr.XORKeyStream(newCipher, newText)
+
 	result = append(result, cipher[:offset]...)
 	result = append(result, newCipher...)
 
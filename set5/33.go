@@ -47,6 +47,7 @@
 package set_five
 
 import (
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
 	"fmt"
@@ -61,6 +62,9 @@ const DH_MAX_RANDOM = 1048576
 type DHGroup struct {
 	P *big.Int
 	G *big.Int
+	// The order of G, if it generates a subgroup of known order. Private keys
+	// are chosen from [1, Q) when it's set.
+	Q *big.Int
 }
 
 type DHSession struct {
@@ -72,19 +76,43 @@ type DHSession struct {
 }
 
 func NewDHSession(p, g *big.Int) *DHSession {
-	c := &DHSession{Group: &DHGroup{p, g}}
+	return NewDHSessionWithGroup(&DHGroup{P: p, G: g})
+}
+
+func NewDHSessionWithGroup(group *DHGroup) *DHSession {
+	c := &DHSession{Group: group}
 	c.generateKeys()
 	return c
 }
 
+// Creates a session with a private key drawn from [min, max] instead of the
+// usual range, e.g. to make the private key small enough to brute force
+func NewDHSessionInRange(group *DHGroup, min, max *big.Int) *DHSession {
+	c := &DHSession{Group: group}
+	c.generateKeysInRange(min, max)
+	return c
+}
+
+// Computes the shared secret with another party's public key:
+//
+//	s = (B ** a) % p
+func (d *DHSession) SharedSecret(publicKey *big.Int) *big.Int {
+	return new(big.Int).Exp(publicKey, d.privateKey, d.Group.P)
+}
+
 func (d *DHSession) GenerateSessionKeys(publicKey *big.Int) {
-	// s = (B ** a) % p
-	bigSessionKey := new(big.Int).Exp(publicKey, d.privateKey, d.Group.P)
+	bigSessionKey := d.SharedSecret(publicKey)
 	d.sessionKey = sha256.Sum256(bigSessionKey.Bytes())
 	d.sha1SessionKey = sha1.Sum(bigSessionKey.Bytes())
 }
 
 func (d *DHSession) generateKeys() {
+	if d.Group.Q != nil {
+		// a = random in [1, q)
+		d.generateKeysInRange(big1, new(big.Int).Sub(d.Group.Q, big1))
+		return
+	}
+
 	random := big.NewInt(int64(cryptopals.RandomInt(1, DH_MAX_RANDOM)))
 	// a = RANDOM % p
 	d.privateKey = new(big.Int).Mod(random, d.Group.P)
@@ -92,6 +120,18 @@ func (d *DHSession) generateKeys() {
 	d.PublicKey = new(big.Int).Exp(d.Group.G, d.privateKey, d.Group.P)
 }
 
+// a = random in [min, max]
+func (d *DHSession) generateKeysInRange(min, max *big.Int) {
+	size := new(big.Int).Sub(max, min)
+	random, err := rand.Int(rand.Reader, size.Add(size, big1))
+	if err != nil {
+		panic(err)
+	}
+	d.privateKey = random.Add(random, min)
+	// A = (g**a) % p
+	d.PublicKey = new(big.Int).Exp(d.Group.G, d.privateKey, d.Group.P)
+}
+
 func GetNISTParams() (*big.Int, *big.Int, error) {
 	g := big.NewInt(2)
 	p, ok := new(big.Int).SetString("ffffffffffffffffc90fdaa22168c234c4c6628b80dc1cd129024e088a67cc74020bbea63b139b22514a08798e3404ddef9519b3cd3a431b302b0a6df25f14374fe1356d6d51c245e485b576625e7ec6f44c42e9a637ed6b0bff5cb6f406b7edee386bfb5a899fa5ae9f24117c4b1fe649286651ece45b3dc2007cb8a163bf0598da48361c55d39a69163fa8fd24cf5f83655d23dca3ad961c62f356208552bb9ed529077096966d670c354e4abc9804f1746c08ca237327ffffffffffffffff", 16)
@@ -55,3 +55,43 @@ func TestDHSessionNIST(t *testing.T) {
 
 	t.Logf("Session key: %v", alice.sessionKey)
 }
+
+func TestDHSessionWithSubgroup(t *testing.T) {
+	// g = 4 generates the subgroup of order 11 mod 23
+	group := &DHGroup{P: big.NewInt(23), G: big.NewInt(4), Q: big.NewInt(11)}
+
+	for i := 0; i < 100; i++ {
+		alice := NewDHSessionWithGroup(group)
+		bob := NewDHSessionWithGroup(group)
+
+		if alice.privateKey.Sign() <= 0 || alice.privateKey.Cmp(group.Q) >= 0 {
+			t.Fatalf("Private key out of range: %d", alice.privateKey)
+		}
+
+		s := alice.SharedSecret(bob.PublicKey)
+		if s.Cmp(bob.SharedSecret(alice.PublicKey)) != 0 {
+			t.Errorf("Shared secrets do not match!\n\n%v\n%v", alice, bob)
+		}
+	}
+}
+
+func TestDHSessionInRange(t *testing.T) {
+	group := &DHGroup{P: big.NewInt(23), G: big.NewInt(5)}
+	min, max := big.NewInt(3), big.NewInt(6)
+
+	seen := make(map[int64]bool)
+	for i := 0; i < 200; i++ {
+		session := NewDHSessionInRange(group, min, max)
+		if session.privateKey.Cmp(min) < 0 || session.privateKey.Cmp(max) > 0 {
+			t.Fatalf("Private key out of range: %d", session.privateKey)
+		}
+		if y := new(big.Int).Exp(group.G, session.privateKey, group.P); y.Cmp(session.PublicKey) != 0 {
+			t.Fatalf("Public key doesn't match the private key")
+		}
+		seen[session.privateKey.Int64()] = true
+	}
+
+	if len(seen) != 4 {
+		t.Errorf("Expected to see all 4 private keys in the range, saw %d", len(seen))
+	}
+}
@@ -39,44 +39,63 @@ package set_five
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"errors"
 	"math/big"
 )
 
 const (
-	PRIME_BITS = 1024
-	e          = 3
+	// Size of the modulus in bits
+	RSA_BITS = 2048
+	e        = 3
 )
 
 var big1 = big.NewInt(1)
 
-func RSAGenerate() (*rsa.PrivateKey, error) {
-	key := &rsa.PrivateKey{}
-	key.E = e
+// Generates an RSA key with an e of 3 and a modulus of `bits` bits
+func RSAGenerate(bits int) (*rsa.PrivateKey, error) {
+	// rand.Prime sets the top two bits of each prime, so the product of the
+	// two is always the full length.
+	pBits := (bits + 1) / 2
+	qBits := bits - pBits
 
-	// Sometimes D = 1 during key generation, so loop until
-	// we generate a valid private key.
+	// Sometimes D = 1 during key generation, or e isn't coprime with the
+	// totient and there is no inverse at all, so loop until we generate a
+	// valid private key.
 	for {
-		p, err := rand.Prime(rand.Reader, PRIME_BITS)
+		p, err := rand.Prime(rand.Reader, pBits)
 		if err != nil {
 			return &rsa.PrivateKey{}, err
 		}
-		q, err := rand.Prime(rand.Reader, PRIME_BITS)
+		q, err := rand.Prime(rand.Reader, qBits)
 		if err != nil {
 			return &rsa.PrivateKey{}, err
 		}
-		key.Primes = []*big.Int{p, q}
-		key.N = new(big.Int).Mul(p, q)
 
-		et := new(big.Int).Sub(p, big1)
-		et.Mul(et, new(big.Int).Sub(q, big1))
-		key.D = new(big.Int).ModInverse(big.NewInt(e), et)
-
-		if key.D.Cmp(big1) > 0 {
-			break
+		n, d, err := RSAKeyFromPrimes(p, q, big.NewInt(e))
+		if err == nil && d.Cmp(big1) > 0 {
+			return &rsa.PrivateKey{
+				PublicKey: rsa.PublicKey{N: n, E: e},
+				D:         d,
+				Primes:    []*big.Int{p, q},
+			}, nil
 		}
 	}
+}
+
+// Builds an RSA key from the primes `p` and `q`, returning the modulus and the
+// private exponent for the public exponent `exp`. It takes a big.Int exponent
+// because not every key fits in an rsa.PublicKey.
+func RSAKeyFromPrimes(p, q, exp *big.Int) (n, d *big.Int, err error) {
+	n = new(big.Int).Mul(p, q)
+
+	et := new(big.Int).Sub(p, big1)
+	et.Mul(et, new(big.Int).Sub(q, big1))
+	d = new(big.Int).ModInverse(exp, et)
+	if d == nil {
+		return nil, nil, errors.New("e is not coprime with the totient")
+	}
 
-	return key, nil
+	return n, d, nil
 }
 
 func RSAEncrypt(m []byte, pub *rsa.PublicKey) []byte {
@@ -14,16 +14,28 @@ func TestModInverse(t *testing.T) {
 }
 
 func TestRSAGenerate(t *testing.T) {
-	_, err := RSAGenerate()
+	_, err := RSAGenerate(RSA_BITS)
 	if err != nil {
 		t.Error(err)
 	}
 }
 
+func TestRSAGenerateSizes(t *testing.T) {
+	for _, bits := range []int{256, 768, 1024} {
+		key, err := RSAGenerate(bits)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if key.N.BitLen() != bits {
+			t.Errorf("Incorrect modulus size. Expected: %d, Got: %d", bits, key.N.BitLen())
+		}
+	}
+}
+
 func TestRSAEncryptDecrypt(t *testing.T) {
 	input := []byte("GO NINJA GO NINJA GO")
 	for i := 0; i < 10; i++ {
-		key, err := RSAGenerate()
+		key, err := RSAGenerate(RSA_BITS)
 		if err != nil {
 			t.Errorf("Error generating RSA key: %s", err)
 		}
@@ -34,3 +46,18 @@ func TestRSAEncryptDecrypt(t *testing.T) {
 		}
 	}
 }
+
+func TestRSAKeyFromPrimes(t *testing.T) {
+	n, d, err := RSAKeyFromPrimes(big.NewInt(61), big.NewInt(53), big.NewInt(17))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Int64() != 3233 || d.Int64() != 2753 {
+		t.Errorf("Expected n = 3233, d = 2753, got n = %d, d = %d", n, d)
+	}
+
+	// 3 divides p-1, so it has no inverse mod the totient
+	if _, _, err := RSAKeyFromPrimes(big.NewInt(7), big.NewInt(11), big.NewInt(3)); err == nil {
+		t.Error("Expected an error when e isn't coprime with the totient")
+	}
+}
@@ -54,6 +54,8 @@ package set_five
 import (
 	"crypto/rsa"
 	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
 )
 
 type KeyAndCipher struct {
@@ -62,27 +64,25 @@ type KeyAndCipher struct {
 }
 
 func CRTAttack(keys [3]KeyAndCipher) []byte {
-	result := big.NewInt(0)
-
-	N := new(big.Int).Mul(keys[0].Key.N, keys[1].Key.N)
-	N.Mul(N, keys[2].Key.N)
-
+	var residues, moduli []*big.Int
 	for _, key := range keys {
-		product := big.NewInt(1)
-		product.Mul(product, new(big.Int).SetBytes(key.Cipher))
-		product.Mul(product, new(big.Int).Div(N, key.Key.N))
-		product.Mul(product, new(big.Int).ModInverse(new(big.Int).Div(N, key.Key.N), key.Key.N))
-		result.Add(result, product)
+		residues = append(residues, new(big.Int).SetBytes(key.Cipher))
+		moduli = append(moduli, key.Key.N)
+	}
+
+	result, _, err := cryptopals.CRT(residues, moduli)
+	if err != nil {
+		panic(err)
 	}
 
-	return cubeRoot(result.Mod(result, N)).Bytes()
+	return CubeRoot(result).Bytes()
 }
 
 func BroadcastRSA(plaintext []byte) [3]KeyAndCipher {
 	var result [3]KeyAndCipher
 
 	for i := 0; i < 3; i++ {
-		key, err := RSAGenerate()
+		key, err := RSAGenerate(RSA_BITS)
 		if err != nil {
 			panic(err)
 		}
@@ -95,8 +95,9 @@ func BroadcastRSA(plaintext []byte) [3]KeyAndCipher {
 	return result
 }
 
+// Integer cube root of `cube`, rounded down
 // Thanks Filippo!
-func cubeRoot(cube *big.Int) *big.Int {
+func CubeRoot(cube *big.Int) *big.Int {
 	var big3 = big.NewInt(3)
 
 	x := new(big.Int).Rsh(cube, uint(cube.BitLen())/3*2)
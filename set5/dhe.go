@@ -6,6 +6,7 @@ import (
 	"net"
 
 	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
 )
 
 const (
@@ -17,6 +18,12 @@ type DHExchange struct {
 	PublicKey *big.Int
 }
 
+// The elliptic curve version of DHExchange
+type ECDHExchange struct {
+	Group     *ec.Group
+	PublicKey *ec.Point
+}
+
 type DHClient struct {
 	Name string
 	// Embed the TCPClient
@@ -37,6 +44,11 @@ func (c *DHClient) ReadDHE() DHExchange {
 	return bob.(DHExchange)
 }
 
+func (c *DHClient) ReadECDHE() ECDHExchange {
+	bob := c.ReadMessage(TCP_ECDHE)
+	return bob.(ECDHExchange)
+}
+
 func (c *DHClient) ReadEncrypted() ([]byte, error) {
 	key := c.session.sha1SessionKey[:KEYSIZE]
 	m := c.ReadMessage(TCP_BYTES)
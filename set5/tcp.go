@@ -14,6 +14,7 @@ const (
 	TCP_SRP_LOGIN             = 0x3
 	TCP_SRP_LOGIN_RESP        = 0x4
 	TCP_SIMPLE_SRP_LOGIN_RESP = 0x5
+	TCP_ECDHE                 = 0x6
 )
 
 type TCPClient struct {
@@ -60,6 +61,14 @@ func (c *TCPClient) ReadMessage(kind byte) interface{} {
 		}
 		return decoded
 
+	case TCP_ECDHE:
+		var decoded ECDHExchange
+		err := decoder.Decode(&decoded)
+		if err != nil {
+			panic(err)
+		}
+		return decoded
+
 	case TCP_SRP_LOGIN:
 		var decoded SRPLogin
 		err := decoder.Decode(&decoded)
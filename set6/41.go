@@ -67,7 +67,12 @@ type RSAOracle struct {
 }
 
 func NewRSAOracle() *RSAOracle {
-	privKey, err := set_five.RSAGenerate()
+	return NewRSAOracleWithSize(set_five.RSA_BITS)
+}
+
+// Creates an oracle with a modulus of `bits` bits
+func NewRSAOracleWithSize(bits int) *RSAOracle {
+	privKey, err := set_five.RSAGenerate(bits)
 	if err != nil {
 		panic(err)
 	}
@@ -63,3 +63,118 @@
  */
 
 package set6
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"crypto/sha1"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+// The ASN.1 DigestInfo prefix for a SHA-1 hash. This is the "ASN.1 GOOP" from
+// the problem description.
+var sha1DigestInfo = []byte{
+	0x30, 0x21, 0x30, 0x09, 0x06, 0x05, 0x2b, 0x0e,
+	0x03, 0x02, 0x1a, 0x05, 0x00, 0x04, 0x14,
+}
+
+// Length of the modulus in bytes
+func keySize(pub *rsa.PublicKey) int {
+	return (pub.N.BitLen() + 7) / 8
+}
+
+// Formats a PKCS#1 v1.5 signature block of `size` bytes for a SHA-1 hash:
+//
+//	00h 01h ffh ffh ... ffh ffh 00h ASN.1 GOOP HASH
+func PKCS1v15SignaturePad(hashed []byte, size int) []byte {
+	tLen := len(sha1DigestInfo) + len(hashed)
+	block := bytes.Repeat([]byte{0xff}, size)
+	block[0] = 0x00
+	block[1] = 0x01
+	block[size-tLen-1] = 0x00
+	copy(block[size-tLen:], sha1DigestInfo)
+	copy(block[size-len(hashed):], hashed)
+	return block
+}
+
+// Signs the SHA-1 hash of `message` with PKCS#1 v1.5 padding
+func RSASign(message []byte, priv *rsa.PrivateKey) []byte {
+	hashed := sha1.Sum(message)
+	block := PKCS1v15SignaturePad(hashed[:], keySize(&priv.PublicKey))
+	return set_five.RSADecrypt(block, priv)
+}
+
+// "Decrypts" a signature by cubing it, and left pads the result back out to
+// the length of the modulus since big.Int drops the leading zero bytes.
+func rsaOpenSignature(sig []byte, pub *rsa.PublicKey) []byte {
+	size := keySize(pub)
+	block := set_five.RSAEncrypt(sig, pub)
+	if len(block) > size {
+		return []byte{}
+	}
+	return append(make([]byte, size-len(block)), block...)
+}
+
+// Verifies a PKCS#1 v1.5 SHA-1 signature by rebuilding the entire expected
+// block and comparing it with the decrypted signature.
+func RSAVerify(message, sig []byte, pub *rsa.PublicKey) bool {
+	hashed := sha1.Sum(message)
+	expected := PKCS1v15SignaturePad(hashed[:], keySize(pub))
+	return bytes.Equal(rsaOpenSignature(sig, pub), expected)
+}
+
+// Verifies a PKCS#1 v1.5 SHA-1 signature the broken way: it checks for
+// 00h 01h, skips over any number of ffh bytes, and then looks for 00h ASN.1
+// HASH. Whatever comes after the hash is never looked at, so the hash doesn't
+// need to be right-justified in the block.
+func SloppyRSAVerify(message, sig []byte, pub *rsa.PublicKey) bool {
+	hashed := sha1.Sum(message)
+	block := rsaOpenSignature(sig, pub)
+
+	if len(block) < 2 || block[0] != 0x00 || block[1] != 0x01 {
+		return false
+	}
+
+	i := 2
+	for i < len(block) && block[i] == 0xff {
+		i++
+	}
+	if i >= len(block) || block[i] != 0x00 {
+		return false
+	}
+	i++
+
+	if !bytes.HasPrefix(block[i:], sha1DigestInfo) {
+		return false
+	}
+	i += len(sha1DigestInfo)
+
+	return bytes.HasPrefix(block[i:], hashed[:])
+}
+
+// Forges a signature for `message` which will be accepted by SloppyRSAVerify
+// under the public key `pub`, provided that e=3.
+//
+// We format a block with only a single ffh byte of padding, followed by the
+// ASN.1 GOOP and the hash, and fill everything after the hash with ffh bytes.
+//
+//	00h 01h ffh 00h ASN.1 GOOP HASH ffh ffh ... ffh
+//
+// The cube root (rounded down) of this block is our signature. Rounding down
+// only changes the trailing "garbage" bytes when the signature is cubed,
+// because the difference between the block and the cube is much smaller than
+// the number of garbage bits.
+func ForgeSignature(message []byte, pub *rsa.PublicKey) []byte {
+	hashed := sha1.Sum(message)
+
+	block := bytes.Repeat([]byte{0xff}, keySize(pub))
+	block[0] = 0x00
+	block[1] = 0x01
+	block[3] = 0x00
+	copy(block[4:], sha1DigestInfo)
+	copy(block[4+len(sha1DigestInfo):], hashed[:])
+
+	return set_five.CubeRoot(new(big.Int).SetBytes(block)).Bytes()
+}
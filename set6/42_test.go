@@ -0,0 +1,44 @@
+package set6
+
+import (
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+func TestRSASignVerify(t *testing.T) {
+	message := []byte("hi mom")
+	key, err := set_five.RSAGenerate(set_five.RSA_BITS)
+	if err != nil {
+		t.Fatalf("Error generating RSA key: %s", err)
+	}
+
+	sig := RSASign(message, key)
+
+	if !RSAVerify(message, sig, &key.PublicKey) {
+		t.Errorf("Valid signature was rejected")
+	}
+	if !SloppyRSAVerify(message, sig, &key.PublicKey) {
+		t.Errorf("Valid signature was rejected by the sloppy verifier")
+	}
+	if RSAVerify([]byte("hi dad"), sig, &key.PublicKey) {
+		t.Errorf("Signature was accepted for the wrong message")
+	}
+}
+
+func TestForgeSignature(t *testing.T) {
+	message := []byte("hi mom")
+	key, err := set_five.RSAGenerate(set_five.RSA_BITS)
+	if err != nil {
+		t.Fatalf("Error generating RSA key: %s", err)
+	}
+
+	forged := ForgeSignature(message, &key.PublicKey)
+
+	if !SloppyRSAVerify(message, forged, &key.PublicKey) {
+		t.Errorf("Forged signature was rejected by the sloppy verifier")
+	}
+	if RSAVerify(message, forged, &key.PublicKey) {
+		t.Errorf("Forged signature was accepted by the strict verifier")
+	}
+}
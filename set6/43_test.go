@@ -0,0 +1,89 @@
+package set6
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"math/big"
+	"testing"
+)
+
+const MESSAGE_43 = "For those that envy a MC it can be hazardous to your health\nSo be friendly, a matter of life and death, just like a etch-a-sketch\n"
+
+func TestDSASignVerify(t *testing.T) {
+	message := []byte("hi mom")
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewDSAKey(group)
+	if err != nil {
+		t.Fatalf("Error generating DSA key: %s", err)
+	}
+
+	sig, err := key.Sign(message)
+	if err != nil {
+		t.Fatalf("Error signing message: %s", err)
+	}
+
+	if !DSAVerify(message, sig, key.PublicKey()) {
+		t.Errorf("Valid signature was rejected")
+	}
+	if DSAVerify([]byte("hi dad"), sig, key.PublicKey()) {
+		t.Errorf("Signature was accepted for the wrong message")
+	}
+}
+
+func TestDSAHash(t *testing.T) {
+	expected := "d2d0714f014a9784047eaeccf956520045c45265"
+	result := hex.EncodeToString(DSAHash([]byte(MESSAGE_43)).Bytes())
+	if result != expected {
+		t.Errorf("Incorrect hash.\nExpected:\t%s\nGot:\t\t%s", expected, result)
+	}
+}
+
+func TestRecoverPrivateKeyFromNonce(t *testing.T) {
+	message := []byte("hi mom")
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		key, err := NewDSAKey(group)
+		if err != nil {
+			t.Fatalf("Error generating DSA key: %s", err)
+		}
+
+		k, _ := randomNonZero(group.Q)
+		sig := key.signWithNonce(message, k)
+
+		x := RecoverPrivateKeyFromNonce(message, sig, k, key.PublicKey())
+		if x.Cmp(key.x) != 0 {
+			t.Errorf("Recovered incorrect private key.\nExpected:\t%v\nGot:\t\t%v", key.x, x)
+		}
+	}
+}
+
+func TestBruteForceNonce(t *testing.T) {
+	expected := "0954edd5e0afe5542a4adf012611a91912a3ec16"
+
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, _ := new(big.Int).SetString("84ad4719d044495496a3201c8ff484feb45b962e7302e56a392aee4abab3e4bdebf2955b4736012f21a08084056b19bcd7fee56048e004e44984e2f411788efdc837a0d2e5abb7b555039fd243ac01f0fb2ed1dec568280ce678e931868d23eb095fde9d3779191b8c0299d6e07bbb283e6633451e535c45513b2d33c99ea17", 16)
+	r, _ := new(big.Int).SetString("548099063082341131477253921760299949438196259240", 10)
+	s, _ := new(big.Int).SetString("857042759984254168557880549501802188789837994940", 10)
+
+	pub := &DSAPublicKey{group, y}
+	x, err := BruteForceNonce([]byte(MESSAGE_43), &DSASignature{r, s}, pub, 0, 1<<16)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := sha1.Sum([]byte(x.Text(16)))
+	if result := hex.EncodeToString(fingerprint[:]); result != expected {
+		t.Errorf("Incorrect private key fingerprint.\nExpected:\t%s\nGot:\t\t%s", expected, result)
+	}
+}
@@ -0,0 +1,194 @@
+/*
+ * DSA nonce recovery from repeated nonce
+ *
+ * Cryptanalytic MVP award.
+ * This attack (in an elliptic curve group) broke the PS3. It is a great, great
+ * attack.
+ *
+ * In this file find a collection of DSA-signed messages. (NB: each msg has a
+ * trailing space.)
+ *
+ * These were signed under the following pubkey:
+ *
+ *     y = 2d026f4bf30195ede3a088da85e398ef869611d0f68f0713d51c9c1a3a26c951
+ *         05d915e2d8cdf26d056b86b8a7b85519b1c23cc3ecdc6062650462e3063bd179
+ *         c2a6581519f674a61f1d89a1fff27171ebc1b93d4dc57bceb7ae2430f98a6a4d
+ *         83d8279ee65d71c1203d2c96d65ebbf7cce9d32971c3de5084cce04a2e147821
+ *
+ * (using the same domain parameters as the previous exercise)
+ *
+ * It should not be hard to find the messages for which we have accidentally
+ * used a repeated "k". Given a pair of such messages, you can discover the "k"
+ * we used with the following formula:
+ *
+ *             (m1 - m2)
+ *         k = --------- mod q
+ *             (s1 - s2)
+ *
+ * 9th Grade Math: Study It!
+ * If you want to demystify this, work out that equation from the original DSA
+ * equations.
+ *
+ * Basic cyclic group math operations want to screw you.
+ * Remember all this math is mod q; s2 may be larger than s1, for instance,
+ * which isn't a problem if you're doing the subtraction mod q. If you're like
+ * me, you'll definitely lose an hour to forgetting a paren or a mod q. (And
+ * don't forget that modular inverse function!)
+ *
+ * What's my private key? Its SHA-1 (from hex) is:
+ *
+ *     ca8f6f7c66fa362d40760d135b763eb8527d3d52
+ */
+
+package set6
+
+import (
+	"bufio"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+)
+
+// A DSA signed message as it appears in the signature log. Hash is the SHA-1
+// of the message as an integer (the "m" field).
+type SignedMessage struct {
+	Message []byte
+	Hash    *big.Int
+	Sig     *DSASignature
+}
+
+// Reads a log of signed messages from `filename`. Each message is made up of
+// four lines: msg, s, r and m, e.g.
+//
+//	msg: Listen for me, you better listen for me now.
+//	s: 1267396447369736888040262262183731677867615804316
+//	r: 1105520928110492191417703162650245113664610474875
+//	m: a4db3de27e2db3e5ef085ced2bced91b82e0df19
+//
+// Fields out of order or repeated, a message cut off partway, or an m which
+// isn't the hash of msg are all errors.
+func LoadSignedMessages(filename string) ([]SignedMessage, error) {
+	var result []SignedMessage
+	var current SignedMessage
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return result, err
+	}
+	defer file.Close()
+
+	line := 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" {
+			continue
+		}
+
+		parts := strings.SplitN(text, ": ", 2)
+		if len(parts) != 2 {
+			return result, fmt.Errorf("%s:%d: malformed line %q", filename, line, text)
+		}
+
+		// msg starts a message, and every other field belongs to the one
+		// it started
+		if (parts[0] == "msg") != (current.Sig == nil) {
+			return result, fmt.Errorf("%s:%d: incomplete message", filename, line)
+		}
+
+		var ok bool
+		switch parts[0] {
+		case "msg":
+			current = SignedMessage{Message: []byte(parts[1]), Sig: &DSASignature{}}
+		case "s":
+			if current.Sig.S != nil {
+				return result, fmt.Errorf("%s:%d: repeated field %q", filename, line, parts[0])
+			}
+			current.Sig.S, ok = new(big.Int).SetString(parts[1], 10)
+		case "r":
+			if current.Sig.R != nil {
+				return result, fmt.Errorf("%s:%d: repeated field %q", filename, line, parts[0])
+			}
+			current.Sig.R, ok = new(big.Int).SetString(parts[1], 10)
+		case "m":
+			current.Hash, ok = new(big.Int).SetString(parts[1], 16)
+		default:
+			return result, fmt.Errorf("%s:%d: unknown field %q", filename, line, parts[0])
+		}
+
+		if parts[0] != "msg" && !ok {
+			return result, fmt.Errorf("%s:%d: invalid number %q", filename, line, parts[1])
+		}
+
+		// m is the last field of each message
+		if parts[0] == "m" {
+			if current.Message == nil || current.Sig.R == nil || current.Sig.S == nil {
+				return result, fmt.Errorf("%s:%d: incomplete message", filename, line)
+			}
+			// The attack hashes the message itself, so m had better agree
+			if DSAHash(current.Message).Cmp(current.Hash) != 0 {
+				return result, fmt.Errorf("%s:%d: hash doesn't match the message", filename, line)
+			}
+			result = append(result, current)
+			current = SignedMessage{}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return result, err
+	}
+	if current.Sig != nil {
+		return result, fmt.Errorf("%s:%d: incomplete message", filename, line)
+	}
+
+	return result, nil
+}
+
+// Recovers the nonce from two messages which were signed with the same k
+//
+//	k = (m1 - m2) * (s1 - s2)**-1 % q
+func recoverRepeatedNonce(a, b SignedMessage, q *big.Int) *big.Int {
+	ds := new(big.Int).Sub(a.Sig.S, b.Sig.S)
+	ds.Mod(ds, q)
+	dsInv := new(big.Int).ModInverse(ds, q)
+	if dsInv == nil {
+		return nil
+	}
+
+	k := new(big.Int).Sub(a.Hash, b.Hash)
+	k.Mul(k, dsInv)
+	return k.Mod(k, q)
+}
+
+// Finds a pair of messages which were signed with the same nonce and uses it
+// to recover the private key for `pub`.
+//
+// Since r = ((g**k) % p) % q only depends on k, signatures with a repeated
+// nonce will share the same r. We group the signatures by r and try each
+// colliding pair until one of them yields the private key.
+func FindRepeatedNonce(messages []SignedMessage, pub *DSAPublicKey) (*big.Int, error) {
+	byR := make(map[string][]SignedMessage)
+	for _, m := range messages {
+		byR[m.Sig.R.String()] = append(byR[m.Sig.R.String()], m)
+	}
+
+	for _, group := range byR {
+		for i := 0; i < len(group); i++ {
+			for j := i + 1; j < len(group); j++ {
+				k := recoverRepeatedNonce(group[i], group[j], pub.Group.Q)
+				if k == nil {
+					continue
+				}
+
+				x := RecoverPrivateKeyFromNonce(group[i].Message, group[i].Sig, k, pub)
+				if new(big.Int).Exp(pub.Group.G, x, pub.Group.P).Cmp(pub.Y) == 0 {
+					return x, nil
+				}
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("No repeated nonce found in %d messages", len(messages))
+}
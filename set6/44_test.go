@@ -0,0 +1,94 @@
+package set6
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestLoadSignedMessages(t *testing.T) {
+	messages, err := LoadSignedMessages("testdata/44.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(messages) != 11 {
+		t.Fatalf("Incorrect number of messages. Expected: 11, Got: %d", len(messages))
+	}
+
+	for _, m := range messages {
+		if DSAHash(m.Message).Cmp(m.Hash) != 0 {
+			t.Errorf("Hash does not match message %q", m.Message)
+		}
+	}
+}
+
+func TestLoadSignedMessagesMalformed(t *testing.T) {
+	for _, test := range []struct {
+		filename, err string
+	}{
+		{"testdata/44_bad_hash.txt", "hash doesn't match"},
+		{"testdata/44_hash_only.txt", "incomplete message"},
+		{"testdata/44_missing_m.txt", "incomplete message"},
+		{"testdata/44_missing_msg.txt", "incomplete message"},
+		{"testdata/44_reordered.txt", "incomplete message"},
+		{"testdata/44_repeated_field.txt", "repeated field"},
+		{"testdata/44_truncated.txt", "incomplete message"},
+	} {
+		_, err := LoadSignedMessages(test.filename)
+		if err == nil || !strings.Contains(err.Error(), test.err) {
+			t.Errorf("%s: expected an error containing %q, got %v", test.filename, test.err, err)
+		}
+	}
+}
+
+func TestFindRepeatedNonce(t *testing.T) {
+	expected := "ca8f6f7c66fa362d40760d135b763eb8527d3d52"
+
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	y, _ := new(big.Int).SetString("2d026f4bf30195ede3a088da85e398ef869611d0f68f0713d51c9c1a3a26c95105d915e2d8cdf26d056b86b8a7b85519b1c23cc3ecdc6062650462e3063bd179c2a6581519f674a61f1d89a1fff27171ebc1b93d4dc57bceb7ae2430f98a6a4d83d8279ee65d71c1203d2c96d65ebbf7cce9d32971c3de5084cce04a2e147821", 16)
+
+	messages, err := LoadSignedMessages("testdata/44.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := FindRepeatedNonce(messages, &DSAPublicKey{group, y})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint := sha1.Sum([]byte(x.Text(16)))
+	if result := hex.EncodeToString(fingerprint[:]); result != expected {
+		t.Errorf("Incorrect private key fingerprint.\nExpected:\t%s\nGot:\t\t%s", expected, result)
+	}
+}
+
+func TestFindRepeatedNonceNoRepeats(t *testing.T) {
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewDSAKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []SignedMessage
+	for _, m := range []string{"one", "two", "three"} {
+		sig, err := key.Sign([]byte(m))
+		if err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, SignedMessage{[]byte(m), DSAHash([]byte(m)), sig})
+	}
+
+	if _, err := FindRepeatedNonce(messages, key.PublicKey()); err == nil {
+		t.Errorf("Found a repeated nonce in signatures with unique nonces")
+	}
+}
@@ -0,0 +1,91 @@
+/*
+ * DSA parameter tampering
+ *
+ * Take your DSA code from the previous exercise. Imagine it as part of an
+ * algorithm in which the client was allowed to propose domain parameters (the
+ * p and q moduli, and the g generator).
+ *
+ * This would be bad, because attackers could trick victims into accepting bad
+ * parameters. Vaudenay gave two examples of bad generator parameters:
+ * generators that were 0 mod p, and generators that were 1 mod p.
+ *
+ * Use the parameters from the previous exercise, but substitute 0 for "g".
+ * Generate a signature. You will notice something bad. Verify the signature.
+ * Now verify any other signature, for any other string.
+ *
+ * Now, try (p+1) as "g". With this "g", you can generate a magic signature s,
+ * r for any DSA public key that will validate against any string. For
+ * arbitrary z:
+ *
+ *       r = ((y**z) % p) % q
+ *
+ *             r
+ *       s =  --- % q
+ *             z
+ *
+ * Sign "Hello, world". And "Goodbye, world".
+ */
+
+package set6
+
+import (
+	"math/big"
+)
+
+// Returns a copy of `group` with the generator replaced by `g`. This is the
+// DSA equivalent of the malicious "g" values that Eve negotiates in the DH
+// attacks from Challenge 35 (EveGEquals1, EveGEqualsP and EveGEqualsPMinus1).
+func TamperDSAGroup(group *DSAGroup, g *big.Int) *DSAGroup {
+	return &DSAGroup{group.P, group.Q, new(big.Int).Set(g)}
+}
+
+// Signs `message` like Sign, but without rejecting signatures where r or s is
+// 0. This is what a signer that trusts its domain parameters looks like.
+func (d *DSAKey) UnsafeSign(message []byte) (*DSASignature, error) {
+	k, err := randomNonZero(d.Group.Q)
+	if err != nil {
+		return nil, err
+	}
+	return d.signWithNonce(message, k), nil
+}
+
+// Verifies a DSA signature without checking that 0 < r < q and 0 < s < q
+func NaiveDSAVerify(message []byte, sig *DSASignature, pub *DSAPublicKey) bool {
+	return dsaCheck(message, sig, pub)
+}
+
+// Forges a signature which NaiveDSAVerify will accept for any message when
+// g = 0.
+//
+// This sets r to 0, which is always the correct result when g = 0:
+//
+//	v = (((g**u1) * (y**u2)) % p) % q = ((0 * y**u2) % p) % q = 0
+//
+// s can be anything which is invertible mod q.
+func ForgeGEquals0Signature() *DSASignature {
+	return &DSASignature{big.NewInt(0), big.NewInt(1)}
+}
+
+// Generates a "magic" signature which is valid for any message under any
+// public key when g = p + 1. For arbitrary z:
+//
+//	r = ((y**z) % p) % q
+//	s = (r * z**-1) % q
+//
+// Since g = 1 mod p, g**u1 drops out of the verification, and u2 works out to
+// be z:
+//
+//	u2 = (r * w) % q = (r * z * r**-1) % q = z
+//	v  = (((g**u1) * (y**u2)) % p) % q = ((y**z) % p) % q = r
+func ForgeGEqualsPPlus1Signature(pub *DSAPublicKey, z *big.Int) *DSASignature {
+	q := pub.Group.Q
+
+	r := new(big.Int).Exp(pub.Y, z, pub.Group.P)
+	r.Mod(r, q)
+
+	s := new(big.Int).ModInverse(z, q)
+	s.Mul(s, r)
+	s.Mod(s, q)
+
+	return &DSASignature{r, s}
+}
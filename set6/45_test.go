@@ -0,0 +1,65 @@
+package set6
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestDSAMaliciousGEquals0(t *testing.T) {
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewDSAKey(TamperDSAGroup(group, big.NewInt(0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig, err := key.UnsafeSign([]byte("Hello, world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.R.Sign() != 0 {
+		t.Errorf("Expected r to be 0 when g = 0. Got: %v", sig.R)
+	}
+
+	for _, message := range []string{"Hello, world", "Goodbye, world"} {
+		if !NaiveDSAVerify([]byte(message), sig, key.PublicKey()) {
+			t.Errorf("Signature was rejected by the naive verifier for %q", message)
+		}
+		if !NaiveDSAVerify([]byte(message), ForgeGEquals0Signature(), key.PublicKey()) {
+			t.Errorf("Forged signature was rejected by the naive verifier for %q", message)
+		}
+		if DSAVerify([]byte(message), ForgeGEquals0Signature(), key.PublicKey()) {
+			t.Errorf("Forged signature was accepted by the verifier for %q", message)
+		}
+	}
+}
+
+func TestDSAMaliciousGEqualsPPlus1(t *testing.T) {
+	group, err := GetDSAParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Generate the key pair with legitimate parameters and then swap in g
+	key, err := NewDSAKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := new(big.Int).Add(group.P, big1)
+	pub := &DSAPublicKey{TamperDSAGroup(group, g), key.Y}
+
+	sig := ForgeGEqualsPPlus1Signature(pub, big.NewInt(42))
+
+	for _, message := range []string{"Hello, world", "Goodbye, world"} {
+		if !DSAVerify([]byte(message), sig, pub) {
+			t.Errorf("Magic signature was rejected for %q", message)
+		}
+	}
+
+	if DSAVerify([]byte("Hello, world"), sig, key.PublicKey()) {
+		t.Errorf("Magic signature was accepted with the original parameters")
+	}
+}
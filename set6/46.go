@@ -0,0 +1,121 @@
+/*
+ * RSA parity oracle
+ *
+ * When does this ever happen?
+ * This is a bit of a toy problem, but it's very helpful for understanding what
+ * RSA is doing (and also for why pure number-theoretic encryption is
+ * terrifying). Trust us, you want to do this before trying the next challenge.
+ * Also, it's fun.
+ *
+ * Generate a 1024 bit RSA key pair.
+ *
+ * Write an oracle function that uses the private key to answer the question
+ * "is the plaintext of this message even or odd" (is the last bit of the
+ * message 0 or 1). Imagine for instance a server that accepted RSA-encrypted
+ * messages and checked the parity of their decryption to validate them, and
+ * spat out an error if they were of the wrong parity.
+ *
+ * Anyways: function returning true or false based on whether the decrypted
+ * plaintext was even or odd, and nothing else.
+ *
+ * Take the following string and un-Base64 it in your code (without looking at
+ * it!) and encrypt it to the public key, creating a ciphertext:
+ *
+ *     VGhhdCdzIHdoeSBJIGZvdW5kIHlvdSBkb24ndCBwbGF5IGFyb3VuZCB3aXRoIHRoZSBGdW5reSBDb2xkIE1lZGluYQ==
+ *
+ * With your oracle function, you can trivially decrypt the message.
+ *
+ * Here's why:
+ *
+ *   - RSA ciphertexts are just numbers. You can do trivial math on them. You
+ *     can for instance multiply a ciphertext by the RSA-encryption of another
+ *     number; the corresponding plaintext will be the product of those two
+ *     numbers.
+ *   - If you double a ciphertext (multiply it by (2**e)%n), the resulting
+ *     plaintext will (obviously) be either even or odd.
+ *   - If the plaintext after doubling is even, doubling the plaintext didn't
+ *     wrap the modulus --- the modulus is a prime number. That means the
+ *     plaintext is less than half the modulus.
+ *
+ * You can repeatedly apply this heuristic, once per bit of the message,
+ * checking your oracle function each time.
+ *
+ * Your decryption function starts with bounds for the plaintext of [0,n].
+ *
+ * Each iteration of the decryption cuts the bounds in half; either the upper
+ * bound is reduced by half, or the lower bound is.
+ *
+ * After log2(n) iterations, you have the decryption of the message.
+ *
+ * Print the upper bound of the message as a string at each iteration; you'll
+ * see the message decrypt "hollywood style".
+ *
+ * Decrypt the string (after encrypting it to a hidden private key) above.
+ */
+
+package set6
+
+import (
+	"crypto/rsa"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+// Answers whether the plaintext of a ciphertext is even
+type ParityOracle func([]byte) bool
+
+// Returns true if the plaintext of `ciphertext` is even. Unlike Decrypt, this
+// will happily answer for the same ciphertext more than once.
+func (r *RSAOracle) IsEven(ciphertext []byte) bool {
+	plaintext := new(big.Int).SetBytes(set_five.RSADecrypt(ciphertext, r.privateKey))
+	return plaintext.Bit(0) == 0
+}
+
+// Decrypts `cipher` one bit at a time using a parity oracle.
+//
+// Each time we double the plaintext (by multiplying the ciphertext by 2**e),
+// the oracle tells us whether the doubled plaintext wrapped the modulus. Since
+// n is odd, an even result means it didn't wrap and the plaintext is in the
+// lower half of our bounds; an odd result means that it's in the upper half.
+//
+// The bounds are kept as exact fractions of n so we don't lose the last few
+// bits to rounding.
+//
+// If `progress` is non-nil, it's called with the upper bound after each
+// iteration so the plaintext can be watched decrypting "hollywood style".
+func ParityOracleAttack(cipher []byte, pub *rsa.PublicKey, oracle ParityOracle, progress func([]byte)) []byte {
+	n := new(big.Rat).SetInt(pub.N)
+	lower := new(big.Rat)
+	upper := new(big.Rat).Set(n)
+
+	// 2**e % n
+	double := new(big.Int).Exp(big.NewInt(2), big.NewInt(int64(pub.E)), pub.N)
+	c := new(big.Int).SetBytes(cipher)
+	half := big.NewRat(1, 2)
+
+	for i := 0; i < pub.N.BitLen(); i++ {
+		c.Mul(c, double)
+		c.Mod(c, pub.N)
+
+		mid := new(big.Rat).Add(lower, upper)
+		mid.Mul(mid, half)
+
+		if oracle(c.Bytes()) {
+			upper = mid
+		} else {
+			lower = mid
+		}
+
+		if progress != nil {
+			progress(ratFloor(upper).Bytes())
+		}
+	}
+
+	return ratFloor(upper).Bytes()
+}
+
+// Rounds a non-negative rational down to an integer
+func ratFloor(r *big.Rat) *big.Int {
+	return new(big.Int).Quo(r.Num(), r.Denom())
+}
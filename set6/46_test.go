@@ -0,0 +1,53 @@
+package set6
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+const SECRET_46 = "VGhhdCdzIHdoeSBJIGZvdW5kIHlvdSBkb24ndCBwbGF5IGFyb3VuZCB3aXRoIHRoZSBGdW5reSBDb2xkIE1lZGluYQ=="
+
+func TestIsEven(t *testing.T) {
+	r := NewRSAOracle()
+	for _, tt := range []struct {
+		input    []byte
+		expected bool
+	}{
+		{[]byte{0x02}, true},
+		{[]byte{0x03}, false},
+		{[]byte("hi mom"), false},
+		{[]byte("hi dad"), true},
+	} {
+		cipher := r.Encrypt(tt.input)
+		// Ask twice to make sure the oracle doesn't reject repeats
+		for i := 0; i < 2; i++ {
+			if result := r.IsEven(cipher); result != tt.expected {
+				t.Errorf("Incorrect parity for %q. Expected: %v, Got: %v", tt.input, tt.expected, result)
+			}
+		}
+	}
+}
+
+func TestParityOracleAttack(t *testing.T) {
+	secret, err := cryptopals.ReadBase64String(SECRET_46)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := NewRSAOracleWithSize(1024)
+	cipher := r.Encrypt([]byte(secret))
+
+	iterations := 0
+	result := ParityOracleAttack(cipher, r.GetPublicKey(), r.IsEven, func(partial []byte) {
+		iterations++
+	})
+
+	if !bytes.Equal(result, []byte(secret)) {
+		t.Errorf("Decryption failed. Got: %q", result)
+	}
+	if iterations != r.GetPublicKey().N.BitLen() {
+		t.Errorf("Progress callback was called %d times, expected %d", iterations, r.GetPublicKey().N.BitLen())
+	}
+}
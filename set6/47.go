@@ -0,0 +1,286 @@
+/*
+ * Bleichenbacher's PKCS 1.5 Padding Oracle (Simple Case)
+ *
+ * Degree of difficulty: moderate
+ * These next two challenges are the hardest in the entire set.
+ *
+ * Let us Google this for you: "Chosen ciphertext attacks against protocols
+ * based on the RSA encryption standard"
+ *
+ * This is Bleichenbacher's from CRYPTO '98; I get a bunch of .ps versions on
+ * the first search page.
+ *
+ * Read the paper. It describes a padding oracle attack on PKCS#1v1.5. The
+ * attack is similar in spirit to the CBC padding oracle you built earlier;
+ * it's an "adaptive chosen ciphertext attack", which means you start with a
+ * valid ciphertext and repeatedly corrupt it, bouncing the adulterated
+ * ciphertexts off the target to learn things about the original.
+ *
+ * This is a common flaw even in modern cryptosystems that use RSA.
+ *
+ * It's also the most fun you can have building a crypto attack. It involves 9th
+ * grade math, but also has you implementing an algorithm that is complex on
+ * par with finding a minimum cost spanning tree.
+ *
+ * The setup:
+ *
+ *   - Build an oracle function, just like you did in the last exercise, but
+ *     have it check for plaintext[0] == 0 and plaintext[1] == 2.
+ *   - Generate a 256 bit keypair (that is, p and q will each be 128 bit
+ *     primes), [n, e, d].
+ *   - Plug d and n into your oracle function.
+ *   - PKCS1.5-pad a short message, like "kick it, CC", and call it "m".
+ *     Encrypt to to get "c".
+ *   - Decrypt "c" using your padding oracle.
+ *
+ * For this challenge, we've used an untypically small RSA modulus, because it
+ * will make this attack go faster. You'll need to implement the whole attack
+ * from the paper, including the multiple-interval search, in the next
+ * challenge, so you might as well do it here too.
+ *
+ * The paper describes the attack in terms of modular arithmetic in the group
+ * of integers mod n; you'll want a bignum library and your invmod function.
+ */
+
+package set6
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+var big2 = big.NewInt(2)
+var big3 = big.NewInt(3)
+
+// Answers whether the plaintext of a ciphertext is PKCS#1 v1.5 conforming
+type PKCS1Oracle func([]byte) bool
+
+// Formats a PKCS#1 v1.5 encryption block (block type 2) of `size` bytes:
+//
+//	00h 02h PS 00h M
+//
+// where PS is at least 8 random non-zero bytes.
+func PKCS1v15EncryptionPad(message []byte, size int) ([]byte, error) {
+	if len(message) > size-11 {
+		return []byte{}, fmt.Errorf("Message too long for a %d byte block", size)
+	}
+
+	block := make([]byte, size)
+	block[1] = 0x02
+
+	ps := block[2 : size-len(message)-1]
+	for i := range ps {
+		for ps[i] == 0 {
+			b, err := cryptopals.GenerateRandomBytes(1)
+			if err != nil {
+				return []byte{}, err
+			}
+			ps[i] = b[0]
+		}
+	}
+
+	copy(block[size-len(message):], message)
+	return block, nil
+}
+
+// Removes PKCS#1 v1.5 encryption padding from `block`
+func PKCS1v15EncryptionUnpad(block []byte) ([]byte, error) {
+	if len(block) < 11 || block[0] != 0x00 || block[1] != 0x02 {
+		return []byte{}, errors.New("Input is not PKCS#1 v1.5 padded")
+	}
+
+	for i := 2; i < len(block); i++ {
+		if block[i] == 0x00 {
+			if i < 10 {
+				return []byte{}, errors.New("PKCS#1 v1.5 padding string too short")
+			}
+			return block[i+1:], nil
+		}
+	}
+
+	return []byte{}, errors.New("Input is not PKCS#1 v1.5 padded")
+}
+
+// PKCS#1 v1.5 pads `message` and encrypts it with textbook RSA
+func RSAEncryptPKCS1v15(message []byte, pub *rsa.PublicKey) ([]byte, error) {
+	block, err := PKCS1v15EncryptionPad(message, keySize(pub))
+	if err != nil {
+		return []byte{}, err
+	}
+	return set_five.RSAEncrypt(block, pub), nil
+}
+
+// Returns true if the plaintext of `ciphertext` starts with 00h 02h. Nothing
+// else about the padding is checked.
+func (r *RSAOracle) IsPKCS1Conforming(ciphertext []byte) bool {
+	plaintext := set_five.RSADecrypt(ciphertext, r.privateKey)
+	// big.Int drops the leading zero, so a conforming plaintext is exactly
+	// one byte shorter than the modulus and starts with 02h
+	return len(plaintext) == keySize(r.GetPublicKey())-1 && plaintext[0] == 0x02
+}
+
+type bleichenbacher struct {
+	pub    *rsa.PublicKey
+	oracle PKCS1Oracle
+	c0     *big.Int
+	e      *big.Int
+	// A conforming plaintext is between 2B and 3B - 1
+	twoB   *big.Int
+	threeB *big.Int
+}
+
+// Multiplies c0 by s**e and asks the oracle if the result is conforming
+func (b *bleichenbacher) conforming(s *big.Int) bool {
+	c := new(big.Int).Exp(s, b.e, b.pub.N)
+	c.Mul(c, b.c0)
+	c.Mod(c, b.pub.N)
+	return b.oracle(c.Bytes())
+}
+
+// Searches upwards from `s` for the next conforming value (Steps 2a and 2b)
+func (b *bleichenbacher) searchFrom(s *big.Int) *big.Int {
+	s = new(big.Int).Set(s)
+	for !b.conforming(s) {
+		s.Add(s, big1)
+	}
+	return s
+}
+
+// Step 2c: Searching with one interval left
+//
+// Choose small r and s such that
+//
+//	r >= 2 * (b*s - 2B) / n
+//	(2B + r*n) / b <= s < (3B + r*n) / a
+//
+// until c0 * s**e is conforming.
+func (b *bleichenbacher) searchOneInterval(m interval, prev *big.Int) *big.Int {
+	n := b.pub.N
+
+	r := new(big.Int).Mul(m.b, prev)
+	r.Sub(r, b.twoB)
+	r.Mul(r, big2)
+	r = ceilDiv(r, n)
+
+	for ; ; r.Add(r, big1) {
+		rn := new(big.Int).Mul(r, n)
+
+		lo := ceilDiv(new(big.Int).Add(b.twoB, rn), m.b)
+		hi := ceilDiv(new(big.Int).Add(b.threeB, rn), m.a)
+
+		for s := lo; s.Cmp(hi) < 0; s.Add(s, big1) {
+			if b.conforming(s) {
+				return s
+			}
+		}
+	}
+}
+
+// Step 3: Narrowing the set of solutions
+//
+// For each interval [a, b] in M and every r with
+//
+//	(a*s - 3B + 1) / n <= r <= (b*s - 2B) / n
+//
+// the new set of intervals is the union of
+//
+//	[max(a, (2B + r*n) / s), min(b, (3B - 1 + r*n) / s)]
+func (b *bleichenbacher) narrow(M []interval, s *big.Int) []interval {
+	var result []interval
+	n := b.pub.N
+
+	for _, m := range M {
+		rLo := new(big.Int).Mul(m.a, s)
+		rLo.Sub(rLo, b.threeB)
+		rLo.Add(rLo, big1)
+		rLo = ceilDiv(rLo, n)
+
+		rHi := new(big.Int).Mul(m.b, s)
+		rHi.Sub(rHi, b.twoB)
+		rHi.Div(rHi, n)
+
+		for r := rLo; r.Cmp(rHi) <= 0; r.Add(r, big1) {
+			rn := new(big.Int).Mul(r, n)
+
+			a := ceilDiv(new(big.Int).Add(b.twoB, rn), s)
+			if a.Cmp(m.a) < 0 {
+				a.Set(m.a)
+			}
+
+			hi := new(big.Int).Add(b.threeB, rn)
+			hi.Sub(hi, big1)
+			hi.Div(hi, s)
+			if hi.Cmp(m.b) > 0 {
+				hi.Set(m.b)
+			}
+
+			if a.Cmp(hi) <= 0 {
+				result = append(result, interval{a, hi})
+			}
+		}
+	}
+
+	return mergeIntervals(result)
+}
+
+// Decrypts `cipher` using an oracle which reports whether a ciphertext
+// decrypts to a PKCS#1 v1.5 conforming plaintext.
+//
+// `cipher` must already be conforming, so we can skip the blinding in Step 1
+// of the paper and use s0 = 1. The result is the full padded plaintext block.
+func BleichenbacherAttack(cipher []byte, pub *rsa.PublicKey, oracle PKCS1Oracle) []byte {
+	k := keySize(pub)
+	n := pub.N
+
+	// B = 2**(8(k - 2))
+	B := new(big.Int).Lsh(big1, uint(8*(k-2)))
+
+	b := &bleichenbacher{
+		pub:    pub,
+		oracle: oracle,
+		c0:     new(big.Int).SetBytes(cipher),
+		e:      big.NewInt(int64(pub.E)),
+		twoB:   new(big.Int).Mul(big2, B),
+		threeB: new(big.Int).Mul(big3, B),
+	}
+
+	// Step 1: M0 = {[2B, 3B - 1]}
+	M := []interval{{new(big.Int).Set(b.twoB), new(big.Int).Sub(b.threeB, big1)}}
+	var s *big.Int
+
+	for i := 1; ; i++ {
+		switch {
+		case i == 1:
+			// Step 2a: Starting the search with s1 = n / 3B
+			s = b.searchFrom(ceilDiv(n, b.threeB))
+		case len(M) > 1:
+			// Step 2b: Searching with more than one interval left
+			s = b.searchFrom(new(big.Int).Add(s, big1))
+		default:
+			// Step 2c: Searching with one interval left
+			s = b.searchOneInterval(M[0], s)
+		}
+
+		M = b.narrow(M, s)
+
+		// Step 4: Computing the solution
+		if len(M) == 1 && M[0].a.Cmp(M[0].b) == 0 {
+			m := M[0].a.Bytes()
+			return append(make([]byte, k-len(m)), m...)
+		}
+	}
+}
+
+// Divides x by y, rounding up
+func ceilDiv(x, y *big.Int) *big.Int {
+	q, m := new(big.Int).DivMod(x, y, new(big.Int))
+	if m.Sign() != 0 {
+		q.Add(q, big1)
+	}
+	return q
+}
@@ -0,0 +1,85 @@
+package set6
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPKCS1v15EncryptionPad(t *testing.T) {
+	message := []byte("kick it, CC")
+	block, err := PKCS1v15EncryptionPad(message, 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(block) != 32 || block[0] != 0x00 || block[1] != 0x02 {
+		t.Errorf("Invalid PKCS#1 v1.5 block: %v", block)
+	}
+	if bytes.IndexByte(block[2:], 0x00) != 32-len(message)-3 {
+		t.Errorf("Padding string contains a zero byte: %v", block)
+	}
+
+	result, err := PKCS1v15EncryptionUnpad(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(result, message) {
+		t.Errorf("Unpad failed. Expected: %q, Got: %q", message, result)
+	}
+
+	if _, err := PKCS1v15EncryptionPad(message, 21); err == nil {
+		t.Errorf("Padded a message which was too long for the block")
+	}
+}
+
+func TestPKCS1v15EncryptionUnpadInvalid(t *testing.T) {
+	for _, input := range [][]byte{
+		[]byte("\x00\x01\xff\xff\xff\xff\xff\xff\xff\xff\x00hi"),
+		[]byte("\x00\x02\xff\xff\xff\xff\xff\xff\xff\xff\xffhi"),
+		[]byte("\x00\x02\xff\x00\xff\xff\xff\xff\xff\xff\xffhi"),
+		[]byte("\x00\x02\x00"),
+	} {
+		if _, err := PKCS1v15EncryptionUnpad(input); err == nil {
+			t.Errorf("Unpadded an invalid block: %v", input)
+		}
+	}
+}
+
+func TestIsPKCS1Conforming(t *testing.T) {
+	r := NewRSAOracleWithSize(256)
+
+	cipher, err := RSAEncryptPKCS1v15([]byte("kick it, CC"), r.GetPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.IsPKCS1Conforming(cipher) {
+		t.Errorf("Padded ciphertext was not conforming")
+	}
+
+	if r.IsPKCS1Conforming(r.Encrypt([]byte("kick it, CC"))) {
+		t.Errorf("Unpadded ciphertext was conforming")
+	}
+}
+
+func testBleichenbacherAttack(t *testing.T, bits int) {
+	message := []byte("kick it, CC")
+	r := NewRSAOracleWithSize(bits)
+
+	cipher, err := RSAEncryptPKCS1v15(message, r.GetPublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := BleichenbacherAttack(cipher, r.GetPublicKey(), r.IsPKCS1Conforming)
+	result, err := PKCS1v15EncryptionUnpad(block)
+	if err != nil {
+		t.Fatalf("Recovered plaintext is not padded: %v", block)
+	}
+	if !bytes.Equal(result, message) {
+		t.Errorf("Decryption failed. Got: %q", result)
+	}
+}
+
+func TestBleichenbacherAttack(t *testing.T) {
+	testBleichenbacherAttack(t, 256)
+}
@@ -0,0 +1,40 @@
+package set6
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestMergeIntervals(t *testing.T) {
+	i := func(a, b int64) interval {
+		return interval{big.NewInt(a), big.NewInt(b)}
+	}
+
+	for _, tt := range []struct {
+		input    []interval
+		expected []interval
+	}{
+		{[]interval{}, []interval{}},
+		{[]interval{i(1, 5)}, []interval{i(1, 5)}},
+		{[]interval{i(1, 5), i(3, 8)}, []interval{i(1, 8)}},
+		{[]interval{i(3, 8), i(1, 5)}, []interval{i(1, 8)}},
+		{[]interval{i(1, 5), i(5, 8)}, []interval{i(1, 8)}},
+		{[]interval{i(1, 5), i(2, 3)}, []interval{i(1, 5)}},
+		{[]interval{i(10, 12), i(1, 5), i(6, 8)}, []interval{i(1, 5), i(6, 8), i(10, 12)}},
+	} {
+		result := mergeIntervals(tt.input)
+		if len(result) != len(tt.expected) {
+			t.Errorf("Incorrect number of intervals. Expected: %v, Got: %v", tt.expected, result)
+			continue
+		}
+		for j := range result {
+			if result[j].a.Cmp(tt.expected[j].a) != 0 || result[j].b.Cmp(tt.expected[j].b) != 0 {
+				t.Errorf("Incorrect intervals. Expected: %v, Got: %v", tt.expected, result)
+			}
+		}
+	}
+}
+
+func TestBleichenbacherAttackComplete(t *testing.T) {
+	testBleichenbacherAttack(t, 768)
+}
@@ -0,0 +1,159 @@
+package set6
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"fmt"
+	"math/big"
+)
+
+var big1 = big.NewInt(1)
+
+type DSAGroup struct {
+	P *big.Int
+	Q *big.Int
+	G *big.Int
+}
+
+type DSAPublicKey struct {
+	Group *DSAGroup
+	Y     *big.Int
+}
+
+type DSAKey struct {
+	DSAPublicKey
+	x *big.Int
+}
+
+type DSASignature struct {
+	R *big.Int
+	S *big.Int
+}
+
+// The DSA domain parameters from Challenge 43
+func GetDSAParams() (*DSAGroup, error) {
+	p, ok := new(big.Int).SetString("800000000000000089e1855218a0e7dac38136ffafa72eda7859f2171e25e65eac698c1702578b07dc2a1076da241c76c62d374d8389ea5aeffd3226a0530cc565f3bf6b50929139ebeac04f48c3c84afb796d61e5a4f9a8fda812ab59494232c7d2b4deb50aa18ee9e132bfa85ac4374d7f9091abc3d015efc871a584471bb1", 16)
+	if !ok {
+		return nil, fmt.Errorf("Error setting DSA parameter p from string")
+	}
+	q, ok := new(big.Int).SetString("f4f47f05794b256174bba6e9b396a7707e563c5b", 16)
+	if !ok {
+		return nil, fmt.Errorf("Error setting DSA parameter q from string")
+	}
+	g, ok := new(big.Int).SetString("5958c9d3898b224b12672c0b98e06c60df923cb8bc999d119458fef538b8fa4046c8db53039db620c094c9fa077ef389b5322a559946a71903f990f1f7e0e025e2d7f7cf494aff1a0470f5b64c36b625a097f1651fe775323556fe00b3608c887892878480e99041be601a62166ca6894bdd41a7054ec89f756ba9fc95302291", 16)
+	if !ok {
+		return nil, fmt.Errorf("Error setting DSA parameter g from string")
+	}
+	return &DSAGroup{p, q, g}, nil
+}
+
+func NewDSAKey(group *DSAGroup) (*DSAKey, error) {
+	d := &DSAKey{DSAPublicKey: DSAPublicKey{Group: group}}
+	if err := d.generateKeys(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DSAKey) generateKeys() error {
+	// x = random in [1, q)
+	x, err := randomNonZero(d.Group.Q)
+	if err != nil {
+		return err
+	}
+	d.x = x
+	// y = (g**x) % p
+	d.Y = new(big.Int).Exp(d.Group.G, d.x, d.Group.P)
+	return nil
+}
+
+func (d *DSAKey) PublicKey() *DSAPublicKey {
+	return &d.DSAPublicKey
+}
+
+// Signs the SHA-1 hash of `message` with a random nonce
+func (d *DSAKey) Sign(message []byte) (*DSASignature, error) {
+	for {
+		k, err := randomNonZero(d.Group.Q)
+		if err != nil {
+			return nil, err
+		}
+		sig := d.signWithNonce(message, k)
+		// Start over with a new k if either half of the signature is 0
+		if sig.R.Sign() != 0 && sig.S.Sign() != 0 {
+			return sig, nil
+		}
+	}
+}
+
+// r = ((g**k) % p) % q
+// s = (k**-1 * (H(m) + x*r)) % q
+func (d *DSAKey) signWithNonce(message []byte, k *big.Int) *DSASignature {
+	q := d.Group.Q
+
+	r := new(big.Int).Exp(d.Group.G, k, d.Group.P)
+	r.Mod(r, q)
+
+	s := new(big.Int).Mul(d.x, r)
+	s.Add(s, DSAHash(message))
+	s.Mul(s, new(big.Int).ModInverse(k, q))
+	s.Mod(s, q)
+
+	return &DSASignature{r, s}
+}
+
+// Verifies a DSA signature of `message`
+//
+//	w  = s**-1 % q
+//	u1 = (H(m) * w) % q
+//	u2 = (r * w) % q
+//	v  = (((g**u1) * (y**u2)) % p) % q
+//
+// The signature is valid if v == r
+func DSAVerify(message []byte, sig *DSASignature, pub *DSAPublicKey) bool {
+	q := pub.Group.Q
+
+	if sig.R.Sign() <= 0 || sig.R.Cmp(q) >= 0 {
+		return false
+	}
+	if sig.S.Sign() <= 0 || sig.S.Cmp(q) >= 0 {
+		return false
+	}
+
+	return dsaCheck(message, sig, pub)
+}
+
+func dsaCheck(message []byte, sig *DSASignature, pub *DSAPublicKey) bool {
+	p, q := pub.Group.P, pub.Group.Q
+
+	w := new(big.Int).ModInverse(sig.S, q)
+	if w == nil {
+		return false
+	}
+	u1 := new(big.Int).Mul(DSAHash(message), w)
+	u1.Mod(u1, q)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, q)
+
+	v := new(big.Int).Exp(pub.Group.G, u1, p)
+	v.Mul(v, new(big.Int).Exp(pub.Y, u2, p))
+	v.Mod(v, p)
+	v.Mod(v, q)
+
+	return v.Cmp(sig.R) == 0
+}
+
+// The SHA-1 hash of `message` as an integer
+func DSAHash(message []byte) *big.Int {
+	h := sha1.Sum(message)
+	return new(big.Int).SetBytes(h[:])
+}
+
+// Returns a random number in [1, max)
+func randomNonZero(max *big.Int) (*big.Int, error) {
+	n, err := rand.Int(rand.Reader, new(big.Int).Sub(max, big1))
+	if err != nil {
+		return nil, err
+	}
+	return n.Add(n, big1), nil
+}
@@ -0,0 +1,365 @@
+/*
+ * CBC-MAC Message Forgery
+ *
+ * Let's talk about CBC-MAC.
+ *
+ * CBC-MAC is like this:
+ *
+ *   1. Take the plaintext P.
+ *   2. Encrypt P under CBC with key K, yielding ciphertext C.
+ *   3. Chuck all of C but the last block C[n].
+ *   4. C[n] is the MAC.
+ *
+ * Suppose there's an online banking application, and it carries out user
+ * requests by talking to an API server over the network. Each request looks
+ * like this:
+ *
+ *     message || IV || MAC
+ *
+ * The message looks like this:
+ *
+ *     from=#{from_id}&to=#{to_id}&amount=#{amount}
+ *
+ * Now, write an API server and a web frontend for it. (NOTE: No need to get
+ * ambitious and write actual servers and web apps. Totally fine to go lo-fi on
+ * this one.) The client and server should share a secret key K to sign and
+ * verify messages.
+ *
+ * The API server should accept messages, verify signatures, and carry out each
+ * transaction if the MAC is valid. It's also publicly exposed - the attacker
+ * can submit messages freely assuming he can forge the right MAC.
+ *
+ * The web client should allow the attacker to generate valid messages for
+ * accounts he controls. (Feel free to sanitize params if you're feeling
+ * anal-retentive.) Assume the attacker is in a position to capture and inspect
+ * messages from the client to the API server.
+ *
+ * One thing we haven't discussed is the IV. Assume the client generates a
+ * per-message IV and sends it along with the MAC. That's how CBC works, right?
+ *
+ * Wrong.
+ *
+ * For messages signed under CBC-MAC, an attacker-controlled IV is a liability.
+ * Why? Because yadda yadda first block of plaintext yadda yadda pick one.
+ *
+ * Use this fact to generate a message transferring 1M spacebucks from a target
+ * victim's account into your account.
+ *
+ * I'll wait. Just let me know when you're done.
+ *
+ * ... waiting
+ *
+ * ... waiting
+ *
+ * ... waiting
+ *
+ * All done? Great - I knew you could do it!
+ *
+ * Now let's tune up that protocol a little bit.
+ *
+ * As we now know, you're supposed to use a fixed IV with CBC-MAC, so let's do
+ * that. We'll set ours at 0 for simplicity. This means the IV comes out of the
+ * protocol:
+ *
+ *     message || MAC
+ *
+ * Pretty simple, but we'll also adjust the message. For the purposes of
+ * efficiency, the bank wants to be able to process multiple transactions in a
+ * single request. So the message now looks like this:
+ *
+ *     from=#{from_id}&tx_list=#{transactions}
+ *
+ * With the transaction list formatted like:
+ *
+ *     to:amount(;to:amount)*
+ *
+ * There's still a weakness here: the MAC is vulnerable to length extension
+ * attacks. How?
+ *
+ * Well, the output of CBC-MAC is a valid IV for a new message.
+ *
+ * "But we don't control the IV anymore!"
+ *
+ * With sufficient mastery of CBC, we can fake it.
+ *
+ * Your mission: capture a valid message from your target user. Use length
+ * extension to add a transaction paying the attacker's account 1M spacebucks.
+ *
+ * Hint!
+ * This would be a lot easier if you had full control over the first block of
+ * your message, huh? Maybe you can simulate that.
+ *
+ * Food for thought: How would you modify the protocol to prevent this?
+ */
+
+package set7
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/hmac"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+const blockSize = aes.BlockSize
+
+type Transaction struct {
+	To     int
+	Amount int
+}
+
+// The API server. It shares a key with the clients it hands out, and carries
+// out any transfer which has a valid CBC-MAC.
+type Bank struct {
+	key      []byte
+	balances map[int]int
+}
+
+// The web frontend for a single logged in user. It will only sign transfers
+// from the account it was created for.
+type BankClient struct {
+	bank *Bank
+	id   int
+}
+
+func NewBank(balances map[int]int) *Bank {
+	key, err := cryptopals.GenerateRandomBytes(blockSize)
+	if err != nil {
+		panic(err)
+	}
+	return &Bank{key, balances}
+}
+
+func (b *Bank) Balance(id int) int {
+	return b.balances[id]
+}
+
+func (b *Bank) Client(id int) *BankClient {
+	return &BankClient{b, id}
+}
+
+func (b *Bank) transfer(from int, txs []Transaction) {
+	for _, tx := range txs {
+		b.balances[from] -= tx.Amount
+		b.balances[tx.To] += tx.Amount
+	}
+}
+
+func (b *Bank) verify(message, iv, mac []byte) error {
+	expected, err := cryptopals.CBCMAC(b.key, iv, message)
+	if err != nil {
+		return err
+	}
+	if !hmac.Equal(expected, mac) {
+		return errors.New("Invalid MAC")
+	}
+	return nil
+}
+
+// Splits a query string like "from=1&to=2" into its keys and values
+func parseParams(message []byte) map[string]string {
+	params := make(map[string]string)
+	for _, pair := range strings.Split(string(message), "&") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = kv[1]
+		}
+	}
+	return params
+}
+
+// Signs a transfer from the client's account with a random IV:
+//
+//	from=#{from_id}&to=#{to_id}&amount=#{amount} || IV || MAC
+func (c *BankClient) Transfer(to, amount int) ([]byte, error) {
+	message := []byte(fmt.Sprintf("from=%d&to=%d&amount=%d", c.id, to, amount))
+
+	iv, err := cryptopals.GenerateRandomBytes(blockSize)
+	if err != nil {
+		return []byte{}, err
+	}
+	mac, err := cryptopals.CBCMAC(c.bank.key, iv, message)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return bytes.Join([][]byte{message, iv, mac}, nil), nil
+}
+
+// Verifies and carries out a request signed by BankClient.Transfer
+func (b *Bank) HandleTransfer(request []byte) error {
+	if len(request) < 2*blockSize {
+		return errors.New("Request too short")
+	}
+
+	split := len(request) - 2*blockSize
+	message, iv, mac := request[:split], request[split:split+blockSize], request[split+blockSize:]
+
+	if err := b.verify(message, iv, mac); err != nil {
+		return err
+	}
+
+	params := parseParams(message)
+	from, err := strconv.Atoi(params["from"])
+	if err != nil {
+		return err
+	}
+	to, err := strconv.Atoi(params["to"])
+	if err != nil {
+		return err
+	}
+	amount, err := strconv.Atoi(params["amount"])
+	if err != nil {
+		return err
+	}
+
+	b.transfer(from, []Transaction{{to, amount}})
+	return nil
+}
+
+// Signs a list of transactions from the client's account with a fixed IV of 0:
+//
+//	from=#{from_id}&tx_list=#{transactions} || MAC
+func (c *BankClient) TransferMany(txs []Transaction) ([]byte, error) {
+	var list []string
+	for _, tx := range txs {
+		list = append(list, fmt.Sprintf("%d:%d", tx.To, tx.Amount))
+	}
+	message := []byte(fmt.Sprintf("from=%d&tx_list=%s", c.id, strings.Join(list, ";")))
+
+	mac, err := cryptopals.CBCMAC(c.bank.key, make([]byte, blockSize), message)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	return append(message, mac...), nil
+}
+
+// Verifies and carries out a request signed by BankClient.TransferMany.
+// Transactions which can't be parsed are skipped.
+func (b *Bank) HandleTransferMany(request []byte) error {
+	if len(request) < blockSize {
+		return errors.New("Request too short")
+	}
+
+	split := len(request) - blockSize
+	message, mac := request[:split], request[split:]
+
+	if err := b.verify(message, make([]byte, blockSize), mac); err != nil {
+		return err
+	}
+
+	params := parseParams(message)
+	from, err := strconv.Atoi(params["from"])
+	if err != nil {
+		return err
+	}
+
+	var txs []Transaction
+	for _, tx := range strings.Split(params["tx_list"], ";") {
+		parts := strings.Split(tx, ":")
+		if len(parts) != 2 {
+			continue
+		}
+		to, err := strconv.Atoi(parts[0])
+		if err != nil {
+			continue
+		}
+		amount, err := strconv.Atoi(parts[1])
+		if err != nil {
+			continue
+		}
+		txs = append(txs, Transaction{to, amount})
+	}
+
+	b.transfer(from, txs)
+	return nil
+}
+
+// Forges a request from the account `victim` by rewriting the "from" field of
+// a captured request from BankClient.Transfer.
+//
+// The IV is XORed with the first block of plaintext before it's encrypted, so
+// flipping bits in the IV flips the same bits in the first block. If we flip
+// the IV by (old ^ new), the MAC will be the same for the new first block:
+//
+//	E(IV ^ P1) == E((IV ^ P1 ^ P1') ^ P1')
+//
+// The "from" field has to be in the first block and the new ID has to be the
+// same length as the old one.
+func ForgeTransferFrom(request []byte, victim int) ([]byte, error) {
+	if len(request) < 3*blockSize {
+		return []byte{}, errors.New("Request too short")
+	}
+
+	split := len(request) - 2*blockSize
+	message, iv, mac := request[:split], request[split:split+blockSize], request[split+blockSize:]
+
+	params := parseParams(message)
+	old := []byte("from=" + params["from"])
+	forged := []byte("from=" + strconv.Itoa(victim))
+	if !bytes.HasPrefix(message, old) || len(old) != len(forged) || len(old) > blockSize {
+		return []byte{}, errors.New("Can't rewrite the from field in the first block")
+	}
+
+	newMessage := append(forged, message[len(forged):]...)
+
+	// IV' = IV ^ P1 ^ P1'
+	newIV := append([]byte{}, iv...)
+	if err := cryptopals.FixedXOR(newIV, message[:blockSize]); err != nil {
+		return []byte{}, err
+	}
+	if err := cryptopals.FixedXOR(newIV, newMessage[:blockSize]); err != nil {
+		return []byte{}, err
+	}
+
+	return bytes.Join([][]byte{newMessage, newIV, mac}, nil), nil
+}
+
+// Length extends a captured request from BankClient.TransferMany with a
+// transaction paying `amount` to `to`, using a client for an account we
+// control to sign our half of the message.
+//
+// The MAC of the captured message is the CBC state after its last (padded)
+// block, so it acts like the IV for anything we append. We simulate our own
+// IV by XORing the MAC into the first block of our own signed message A:
+//
+//	M || pad(M) || (A1 ^ MAC(M)) || A2 ... An
+//
+// When this is encrypted, A1 encrypts exactly like it did under the fixed IV
+// of 0, so the forged message has the same MAC as our own. A1 turns into
+// garbage in the forged message, so we fill it with a dummy transaction and
+// put the one we care about after it. If the garbage happens to contain a
+// separator the forgery won't parse, and we need to capture another request.
+func ForgeTransferManyExtension(captured []byte, attacker *BankClient, to, amount int) ([]byte, error) {
+	if len(captured) < blockSize {
+		return []byte{}, errors.New("Request too short")
+	}
+
+	message, mac := captured[:len(captured)-blockSize], captured[len(captured)-blockSize:]
+	padded := cryptopals.PKCS7Pad(blockSize, append([]byte{}, message...))
+
+	ours, err := attacker.TransferMany([]Transaction{{attacker.id, 0}, {to, amount}})
+	if err != nil {
+		return []byte{}, err
+	}
+	if bytes.IndexByte(ours, ';') < blockSize {
+		return []byte{}, errors.New("Transactions must start after the first block")
+	}
+
+	extension := append([]byte{}, ours...)
+	if err := cryptopals.FixedXOR(extension[:blockSize], mac); err != nil {
+		return []byte{}, err
+	}
+
+	if bytes.ContainsAny(extension[:blockSize], "&;") {
+		return []byte{}, errors.New("Extension block contains a separator")
+	}
+
+	return append(padded, extension...), nil
+}
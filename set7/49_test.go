@@ -0,0 +1,107 @@
+package set7
+
+import (
+	"testing"
+)
+
+const (
+	VICTIM   = 1
+	ATTACKER = 2
+	OTHER    = 3
+)
+
+func newTestBank() *Bank {
+	return NewBank(map[int]int{
+		VICTIM:   1000000,
+		ATTACKER: 0,
+		OTHER:    0,
+	})
+}
+
+func TestBankTransfer(t *testing.T) {
+	bank := newTestBank()
+
+	request, err := bank.Client(VICTIM).Transfer(OTHER, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bank.HandleTransfer(request); err != nil {
+		t.Fatal(err)
+	}
+
+	if bank.Balance(VICTIM) != 999900 || bank.Balance(OTHER) != 100 {
+		t.Errorf("Incorrect balances after transfer: %v", bank.balances)
+	}
+
+	// Tamper with the amount
+	request[len(request)-2*blockSize-1] ^= 0x01
+	if err := bank.HandleTransfer(request); err == nil {
+		t.Errorf("Tampered request was accepted")
+	}
+}
+
+func TestForgeTransferFrom(t *testing.T) {
+	bank := newTestBank()
+
+	// Sign a transfer from the attacker's account to itself
+	request, err := bank.Client(ATTACKER).Transfer(ATTACKER, 1000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged, err := ForgeTransferFrom(request, VICTIM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bank.HandleTransfer(forged); err != nil {
+		t.Fatalf("Forged request was rejected: %s", err)
+	}
+
+	if bank.Balance(VICTIM) != 0 || bank.Balance(ATTACKER) != 1000000 {
+		t.Errorf("Incorrect balances after forged transfer: %v", bank.balances)
+	}
+}
+
+func TestBankTransferMany(t *testing.T) {
+	bank := newTestBank()
+
+	request, err := bank.Client(VICTIM).TransferMany([]Transaction{{OTHER, 100}, {ATTACKER, 5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bank.HandleTransferMany(request); err != nil {
+		t.Fatal(err)
+	}
+
+	if bank.Balance(VICTIM) != 999895 || bank.Balance(OTHER) != 100 || bank.Balance(ATTACKER) != 5 {
+		t.Errorf("Incorrect balances after transfer: %v", bank.balances)
+	}
+}
+
+func TestForgeTransferManyExtension(t *testing.T) {
+	bank := newTestBank()
+
+	// Keep capturing requests from the victim until one of them can be
+	// extended cleanly
+	var forged []byte
+	for amount := 1; forged == nil && amount < 100; amount++ {
+		captured, err := bank.Client(VICTIM).TransferMany([]Transaction{{OTHER, amount}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result, err := ForgeTransferManyExtension(captured, bank.Client(ATTACKER), ATTACKER, 1000000); err == nil {
+			forged = result
+		}
+	}
+	if forged == nil {
+		t.Fatal("Unable to forge an extension from any captured request")
+	}
+
+	if err := bank.HandleTransferMany(forged); err != nil {
+		t.Fatalf("Forged request was rejected: %s", err)
+	}
+
+	if bank.Balance(ATTACKER) != 1000000 || bank.Balance(VICTIM) != 0 {
+		t.Errorf("Incorrect balances after forged transfer: %v", bank.balances)
+	}
+}
@@ -0,0 +1,110 @@
+/*
+ * Hashing with CBC-MAC
+ *
+ * Sometimes people try to use CBC-MAC as a hash function.
+ *
+ * This is a bad idea. Matt Green explains:
+ *
+ *     To make a long story short: cryptographic hash functions are public
+ *     functions (i.e., no secret key) that have the property of collision-
+ *     resistance (it's hard to find two messages with the same hash). MACs are
+ *     keyed functions that (typically) provide message unforgeability -- a very
+ *     different property. Moreover, they guarantee this only when the key is
+ *     secret.
+ *
+ * Let's try a simple exercise.
+ *
+ * Hash functions are often used for code verification. This snippet of
+ * JavaScript (with newline):
+ *
+ *     alert('MZA who was that?');
+ *
+ * Hashes to 296b8d7cb78a243dda4d0a61d33bbdd1 under CBC-MAC with a key of
+ * "YELLOW SUBMARINE" and a 0 IV.
+ *
+ * Forge a valid snippet of JavaScript that alerts "Ayo, the Wu is back!" and
+ * hashes to the same value. Ensure that it runs in a browser.
+ *
+ * Extra Credit
+ * Write JavaScript code that downloads your file, checks its CBC-MAC, and
+ * inserts it into the DOM iff it matches the expected hash.
+ */
+
+package set7
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+var hashKey = []byte("YELLOW SUBMARINE")
+
+// CBC-MAC used as a "hash" with a well-known key and a 0 IV
+func CBCMACHash(msg []byte) ([]byte, error) {
+	return cryptopals.CBCMAC(hashKey, make([]byte, blockSize), msg)
+}
+
+// The raw CBC state after encrypting `msg`, which must be a multiple of the
+// block size. No padding is added.
+func cbcState(key, iv, msg []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return []byte{}, err
+	}
+	encrypted := make([]byte, len(msg))
+	cryptopals.NewCBCEncrypter(block, iv).CryptBlocks(encrypted, msg)
+	return encrypted[len(encrypted)-blockSize:], nil
+}
+
+// Builds a JavaScript snippet which starts with `prefix` and has the same
+// CBCMACHash as `target`.
+//
+// The prefix is followed by a "//" comment and padded out to a full block
+// with spaces. The CBC state S after the prefix is then cancelled out by the
+// next block, after which we continue with the rest of the target:
+//
+//	prefix // ... || (T1 ^ S) || T2 ... Tn
+//
+// Since (T1 ^ S) ^ S == T1, everything from here on encrypts just like the
+// target did under the 0 IV and ends up with the same hash. The garbage block
+// and the rest of the target's first line end up in the comment, as long as
+// the garbage doesn't contain a line terminator. If it does, we add another
+// block of spaces to the comment and try again.
+func ForgeCBCMACCollision(target, prefix []byte) ([]byte, error) {
+	if len(target) < blockSize {
+		return []byte{}, errors.New("Target must be at least one block long")
+	}
+
+	forged := append(append([]byte{}, prefix...), "//"...)
+	forged = append(forged, bytes.Repeat([]byte(" "), blockSize-len(forged)%blockSize)...)
+
+	for i := 0; i < 1000; i++ {
+		state, err := cbcState(hashKey, make([]byte, blockSize), forged)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		glue := append([]byte{}, target[:blockSize]...)
+		if err := cryptopals.FixedXOR(glue, state); err != nil {
+			return []byte{}, err
+		}
+
+		if !containsLineTerminator(glue) {
+			return bytes.Join([][]byte{forged, glue, target[blockSize:]}, nil), nil
+		}
+
+		forged = append(forged, bytes.Repeat([]byte(" "), blockSize)...)
+	}
+
+	return []byte{}, errors.New("Unable to find a block without line terminators")
+}
+
+// Checks for anything that JavaScript treats as the end of a line comment
+func containsLineTerminator(b []byte) bool {
+	return bytes.ContainsAny(b, "\r\n") ||
+		bytes.Contains(b, []byte("\xe2\x80\xa8")) ||
+		bytes.Contains(b, []byte("\xe2\x80\xa9"))
+}
@@ -0,0 +1,45 @@
+package set7
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+const TARGET_50 = "alert('MZA who was that?');\n"
+
+func TestCBCMACHash(t *testing.T) {
+	expected := "296b8d7cb78a243dda4d0a61d33bbdd1"
+	hash, err := CBCMACHash([]byte(TARGET_50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := hex.EncodeToString(hash); result != expected {
+		t.Errorf("Incorrect hash.\nExpected:\t%s\nGot:\t\t%s", expected, result)
+	}
+}
+
+func TestForgeCBCMACCollision(t *testing.T) {
+	prefix := []byte("alert('Ayo, the Wu is back!');\n")
+
+	forged, err := ForgeCBCMACCollision([]byte(TARGET_50), prefix)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := CBCMACHash([]byte(TARGET_50))
+	result, _ := CBCMACHash(forged)
+	if !bytes.Equal(result, expected) {
+		t.Errorf("Hashes do not match.\nExpected:\t%x\nGot:\t\t%x", expected, result)
+	}
+
+	if !bytes.HasPrefix(forged, prefix) {
+		t.Errorf("Forged snippet does not start with the prefix: %q", forged)
+	}
+
+	// Everything after the prefix has to be on a single commented line
+	comment := forged[len(prefix):]
+	if !bytes.HasPrefix(comment, []byte("//")) || bytes.IndexAny(comment, "\r\n") != len(comment)-1 {
+		t.Errorf("Garbage is not contained in a single line comment: %q", comment)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+ * Compression Ratio Side-Channel Attacks
+ *
+ * Internet traffic is often compressed to save bandwidth. Until recently, this
+ * included HTTPS headers, and it still includes the contents of responses.
+ *
+ * Why does that matter?
+ *
+ * Well, if you're an attacker with:
+ *
+ *   1. Partial plaintext knowledge and
+ *   2. Partial plaintext control and
+ *   3. Access to a compression oracle
+ *
+ * You've got a pretty good chance to recover any additional unknown plaintext.
+ *
+ * What's a compression oracle? You give it some input and it tells you how
+ * well the full message compresses, i.e. the length of the resultant output.
+ *
+ * This is somewhat similar to the timing attacks we did way back in set 4 in
+ * that we're taking advantage of incidental side channels rather than
+ * attacking the cryptographic mechanisms themselves.
+ *
+ * Scenario: you are running a MITM attack with an eye towards stealing secure
+ * session cookies. You've injected malicious content allowing you to spawn
+ * arbitrary requests and observe them in flight. (The particulars aren't
+ * terribly important, just roll with it.)
+ *
+ * So! Write this oracle:
+ *
+ *     oracle(P) -> length(encrypt(compress(format_request(P))))
+ *
+ * Format the request like this:
+ *
+ *     POST / HTTP/1.1
+ *     Host: hapless.com
+ *     Cookie: sessionid=TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE=
+ *     Content-Length: ((len(P)))
+ *     ((P))
+ *
+ * (Pretend you can't see that session id. You're the attacker.)
+ *
+ * Compress using zlib or whatever.
+ *
+ * Encryption... is actually kind of irrelevant for our purposes, but be a
+ * sport. Just use some stream cipher. Dealer's choice. Random key/IV on every
+ * call to the oracle.
+ *
+ * And then just return the length in bytes.
+ *
+ * Now, the idea here is to leak information using the compression library. A
+ * payload of "sessionid=T" should compress just a little bit better than, say,
+ * "sessionid=S".
+ *
+ * There is one complicating factor. The DEFLATE algorithm operates in terms of
+ * individual bits, but the final message length will be in bytes. Even if you
+ * do find a better compression, the difference may not cross a byte boundary.
+ * So that's a problem.
+ *
+ * You may also get some incorrect hits due to (for example) Huffman encoding.
+ *
+ * So you'll need to use some heuristics to make this work. Be creative.
+ *
+ * Once your attack is working for a stream cipher, switch to a block cipher,
+ * like CBC. You'll need to add some additional padding to make it work.
+ *
+ * Good luck!
+ */
+
+package set7
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+const SESSION_ID = "TmV2ZXIgcmV2ZWFsIHRoZSBXdS1UYW5nIFNlY3JldCE="
+
+// Returns the length of an encrypted, compressed request with a body of `P`
+type CompressionOracle func([]byte) int
+
+const (
+	base64Charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/="
+	// Bytes which never appear in the request, used to pad out our guesses
+	paddingCharset = "!@#$%^&*()-`~[]{}<>|"
+	// The most tied guesses we'll keep around before giving up
+	maxCandidates = 16
+	// The longest session ID we'll try to recover. Ours is 44 characters.
+	maxSessionIDLength = 64
+)
+
+func formatRequest(P []byte) []byte {
+	return []byte(fmt.Sprintf(
+		"POST / HTTP/1.1\nHost: hapless.com\nCookie: sessionid=%s\nContent-Length: %d\n%s",
+		SESSION_ID, len(P), P,
+	))
+}
+
+func compress(data []byte) []byte {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write(data)
+	w.Close()
+	return b.Bytes()
+}
+
+// Compresses and encrypts a request with AES-CTR under a random key and nonce
+func CTRCompressionOracle(P []byte) int {
+	key, err := cryptopals.GenerateRandomBytes(blockSize)
+	if err != nil {
+		panic(err)
+	}
+	encrypted, err := cryptopals.AESCTR(compress(formatRequest(P)), key, rand.Int())
+	if err != nil {
+		panic(err)
+	}
+	return len(encrypted)
+}
+
+// Compresses and encrypts a request with AES-CBC under a random key and IV
+func CBCCompressionOracle(P []byte) int {
+	key, err := cryptopals.GenerateRandomBytes(blockSize)
+	if err != nil {
+		panic(err)
+	}
+	iv, err := cryptopals.GenerateRandomBytes(blockSize)
+	if err != nil {
+		panic(err)
+	}
+	encrypted, err := cryptopals.EncryptAESCBC(compress(formatRequest(P)), key, iv)
+	if err != nil {
+		panic(err)
+	}
+	return len(encrypted)
+}
+
+// Finds padding which puts `body` right on the edge of a block boundary, so
+// that a guess which compresses even one byte better will shrink the output by
+// a whole block.
+//
+// This adds bytes until the length of the output increases, just like
+// DetermineBlockSize does in Challenge 12. With a stream cipher the output
+// length changes with every byte, so this returns almost immediately.
+func findPadding(oracle CompressionOracle, body []byte) []byte {
+	prevLen := oracle(body)
+	for i := 1; i < len(paddingCharset); i++ {
+		padding := []byte(paddingCharset[:i])
+		if oracle(append(padding, body...)) > prevLen {
+			return padding
+		}
+	}
+	return []byte{}
+}
+
+// Recovers the session ID from the Cookie header using only the lengths
+// returned by the compression oracle.
+//
+// Each guess is a "Cookie: sessionid=" prefix followed by what we know of the cookie
+// and one more character. The right character is a longer match against the
+// real cookie and compresses better. DEFLATE counts bits rather than bytes, so
+// more than one guess often ties for the shortest output; we keep all of the
+// tied guesses and extend each of them, since the wrong ones quickly fall
+// behind. The session ID ends when a newline alone compresses best, and we
+// give up if it hasn't after maxSessionIDLength characters.
+func RecoverSessionID(oracle CompressionOracle) (string, error) {
+	// "sessionid=" on its own doesn't always prefer the right first character,
+	// but the whole header name makes for a longer match
+	prefix := "Cookie: sessionid="
+	candidates := []string{""}
+
+	for len(candidates[0]) < maxSessionIDLength {
+		var best []string
+		bestLen := -1
+
+		for _, known := range candidates {
+			// Use a character which can't be part of the session ID to find
+			// the block boundary for this guess
+			padding := findPadding(oracle, []byte(prefix+known+"~"))
+
+			for _, c := range base64Charset + "\n" {
+				guess := append(append([]byte{}, padding...), prefix+known+string(c)...)
+				length := oracle(guess)
+
+				switch {
+				case bestLen == -1 || length < bestLen:
+					bestLen = length
+					best = []string{known + string(c)}
+				case length == bestLen:
+					best = append(best, known+string(c))
+				}
+			}
+		}
+
+		if len(best) == 1 && strings.HasSuffix(best[0], "\n") {
+			return strings.TrimSuffix(best[0], "\n"), nil
+		}
+
+		if len(best) > maxCandidates {
+			return "", errors.New("Unable to recover the session ID: too many guesses compress equally well")
+		}
+		candidates = best
+	}
+
+	return "", fmt.Errorf("Unable to recover the session ID: no end after %d characters", maxSessionIDLength)
+}
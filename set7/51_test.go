@@ -0,0 +1,51 @@
+package set7
+
+import (
+	"bytes"
+	"testing"
+)
+
+func testRecoverSessionID(t *testing.T, oracle CompressionOracle) {
+	result, err := RecoverSessionID(oracle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != SESSION_ID {
+		t.Errorf("Incorrect session ID.\nExpected:\t%s\nGot:\t\t%s", SESSION_ID, result)
+	}
+}
+
+func TestCompressionOracleLeaksMatches(t *testing.T) {
+	right := CTRCompressionOracle([]byte("sessionid=" + SESSION_ID))
+	wrong := CTRCompressionOracle([]byte("sessionid=!@#$%^&*()-`~[]{}<>|!@#$%^&*()-`~[]"))
+	if right >= wrong {
+		t.Errorf("Expected the real session ID to compress better: %d >= %d", right, wrong)
+	}
+}
+
+func TestCBCCompressionOracleIsBlockAligned(t *testing.T) {
+	for _, body := range []string{"", "A", "sessionid=", "hello, world"} {
+		if length := CBCCompressionOracle([]byte(body)); length%blockSize != 0 {
+			t.Errorf("Oracle output of %d bytes is not block aligned", length)
+		}
+	}
+}
+
+func TestRecoverSessionIDCTR(t *testing.T) {
+	testRecoverSessionID(t, CTRCompressionOracle)
+}
+
+func TestRecoverSessionIDCBC(t *testing.T) {
+	testRecoverSessionID(t, CBCCompressionOracle)
+}
+
+// An oracle where "A" always compresses best never finds the end of the
+// session ID
+func TestRecoverSessionIDGivesUp(t *testing.T) {
+	oracle := func(P []byte) int {
+		return 1000 - bytes.Count(P, []byte("A"))
+	}
+	if _, err := RecoverSessionID(oracle); err == nil {
+		t.Error("Expected an error for a session ID with no end")
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ * Iterated Hash Function Multicollisions
+ *
+ * While we're on the topic of hash functions...
+ *
+ * The major design principle of the popular hash functions (MD4, MD5, SHA-1,
+ * SHA-2) is the Merkle-Damgard construction. The basic idea is:
+ *
+ *   1. Take a message M and break it into blocks M[i].
+ *   2. Initialize a hash H (say, all zeroes).
+ *   3. For each block M[i], compute H := C(M[i], H), where C is a
+ *      compression function.
+ *   4. Finally, output H.
+ *
+ * That's oversimplified, but it captures the idea. (Real hash functions add a
+ * bit of padding and the length of the message, etc.)
+ *
+ * There are a few problems with this construction. The one we're going to
+ * focus on now is that it's very easy to generate multicollisions: many
+ * messages that hash to the same value.
+ *
+ * Here's how:
+ *
+ *   1. Starting from the initial state H, find two single-block messages that
+ *      collide. Now you have two messages that hash to the same value, and an
+ *      intermediate state H'.
+ *   2. Starting from H', find two more single-block messages that collide.
+ *      Now you have four messages that hash to the same value: you can pick
+ *      either block from each pair.
+ *   3. Repeat. After n iterations you have 2^n colliding messages, for only n
+ *      times the work of finding a single collision.
+ *
+ * To make this feasible, use a toy hash function with a tiny state (say, 16
+ * bits) built out of something like AES: encrypt each message block under
+ * the current state (padded out to a key), and truncate the output to the
+ * size of the state.
+ *
+ * Write a function f(n) that generates 2^n collisions in this hash function.
+ *
+ * Why does this matter? Well, one reasonable-sounding way to make a stronger
+ * hash function is to cascade two weaker ones:
+ *
+ *     h(x) = f(x) || g(x)
+ *
+ * Intuitively, the strength of h should be the sum of the strengths of f and
+ * g. But using multicollisions, we can find a collision in h for not much more
+ * than the work of attacking g alone:
+ *
+ *   1. Let f be the cheaper hash function with a b1-bit state, and g the more
+ *      expensive one with a b2-bit state.
+ *   2. Generate 2^(b2/2) colliding messages in f.
+ *   3. There's a good chance your messages contain a collision in g.
+ *   4. If not, generate more and try again.
+ *
+ * Verify that your collision works in h, and count the calls to each
+ * compression function to see how much work you did.
+ */
+
+package set7
+
+import (
+	"bytes"
+	"errors"
+	"math"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/mdhash"
+)
+
+// The compression function for an mdhash with a `Size` byte state, which
+// keeps track of how many times it's been called
+type Compressor struct {
+	Size  int
+	Calls int
+}
+
+func NewCompressor(size int) *Compressor {
+	return &Compressor{Size: size}
+}
+
+func (c *Compressor) Compress(state, block []byte) []byte {
+	c.Calls++
+	return mdhash.Compress(state, block)
+}
+
+// Runs the compression function on every block of `message` from `state`
+func (c *Compressor) CompressBlocks(state, message []byte) []byte {
+	for i := 0; i+mdhash.BlockSize <= len(message); i += mdhash.BlockSize {
+		state = c.Compress(state, message[i:i+mdhash.BlockSize])
+	}
+	return state
+}
+
+// Hashes `message` from the initial state, including the padding
+func (c *Compressor) Sum(message []byte) []byte {
+	padded := append(append([]byte{}, message...), mdhash.Pad(uint64(len(message)))...)
+	return c.CompressBlocks(mdhash.IV(c.Size), padded)
+}
+
+func randomBlock() []byte {
+	block, err := cryptopals.GenerateRandomBytes(mdhash.BlockSize)
+	if err != nil {
+		panic(err)
+	}
+	return block
+}
+
+// Finds a block `a` from state `s1` and a block `b` from state `s2` which
+// compress to the same state, and returns the blocks and that state.
+//
+// Random blocks from both states are remembered until one from each side
+// compresses to the same state, which takes about 2^(b/2) calls for a b-bit
+// state by the birthday paradox.
+func (c *Compressor) findCollision(s1, s2 []byte) (a, b, next []byte) {
+	fromS1 := make(map[string][]byte)
+	fromS2 := make(map[string][]byte)
+
+	for {
+		block := randomBlock()
+		h := string(c.Compress(s1, block))
+		if other, ok := fromS2[h]; ok && !bytes.Equal(block, other) {
+			return block, other, []byte(h)
+		}
+		fromS1[h] = block
+
+		block = randomBlock()
+		h = string(c.Compress(s2, block))
+		if other, ok := fromS1[h]; ok && !bytes.Equal(block, other) {
+			return other, block, []byte(h)
+		}
+		fromS2[h] = block
+	}
+}
+
+// Finds two single block messages which collide from `state`, and returns
+// them along with the state they both compress to
+func (c *Compressor) FindCollision(state []byte) (a, b, next []byte) {
+	return c.findCollision(state, state)
+}
+
+// 2^n messages of n blocks which all leave the hash in the same state. Each
+// message picks one of the two blocks from every pair in Blocks.
+type Multicollision struct {
+	Blocks [][2][]byte
+	State  []byte
+}
+
+// Generates a multicollision of 2^n messages from `state`
+func (c *Compressor) Multicollision(state []byte, n int) *Multicollision {
+	m := &Multicollision{State: state}
+	for i := 0; i < n; i++ {
+		c.Extend(m)
+	}
+	return m
+}
+
+// Doubles the number of messages in `m` by finding one more collision
+func (c *Compressor) Extend(m *Multicollision) {
+	a, b, next := c.FindCollision(m.State)
+	m.Blocks = append(m.Blocks, [2][]byte{a, b})
+	m.State = next
+}
+
+// The number of messages in the multicollision
+func (m *Multicollision) Len() int {
+	return 1 << uint(len(m.Blocks))
+}
+
+// Returns message `i`, where bit j of `i` picks the block at position j
+func (m *Multicollision) Message(i int) []byte {
+	var message []byte
+	for j, pair := range m.Blocks {
+		message = append(message, pair[(i>>uint(j))&1]...)
+	}
+	return message
+}
+
+// The number of compression function calls we expect it to take to find 2^n
+// colliding messages with a state of `size` bytes: n times the birthday bound
+func MulticollisionWork(size, n int) float64 {
+	return float64(n) * mdhash.CollisionWork(size)
+}
+
+// The cascaded hash f(x) || g(x) of `message`
+func CascadeSum(f, g *Compressor, message []byte) []byte {
+	return append(f.Sum(message), g.Sum(message)...)
+}
+
+// Finds two messages which collide in the cascaded hash f(x) || g(x).
+//
+// We generate 2^(b2/2) messages which all collide in the cheaper hash f, and
+// look for a pair among them which also collides in g. If there isn't one, we
+// double the number of messages and look again.
+func CascadeCollision(f, g *Compressor) ([]byte, []byte, error) {
+	if f.Size > g.Size {
+		return []byte{}, []byte{}, errors.New("f must have the smaller state")
+	}
+
+	m := f.Multicollision(mdhash.IV(f.Size), 4*g.Size)
+
+	for {
+		seen := make(map[string]int)
+		for i := 0; i < m.Len(); i++ {
+			h := string(g.Sum(m.Message(i)))
+			if j, ok := seen[h]; ok {
+				return m.Message(j), m.Message(i), nil
+			}
+			seen[h] = i
+		}
+		f.Extend(m)
+	}
+}
+
+// The number of compression function calls we expect it to take to find a
+// collision in f(x) || g(x): b2/2 collisions in f, and 2^(b2/2) messages of
+// b2/2 blocks (plus padding) hashed with g.
+func CascadeWork(fSize, gSize int) (fCalls, gCalls float64) {
+	n := 4 * gSize
+	fCalls = MulticollisionWork(fSize, n)
+	gCalls = math.Exp2(float64(n)) * float64(n+1)
+	return
+}
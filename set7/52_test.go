@@ -0,0 +1,77 @@
+package set7
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/mdhash"
+)
+
+func TestFindCollision(t *testing.T) {
+	c := NewCompressor(2)
+	state := mdhash.IV(2)
+
+	a, b, next := c.FindCollision(state)
+	if bytes.Equal(a, b) {
+		t.Fatal("Blocks are identical")
+	}
+	if ha, hb := mdhash.Compress(state, a), mdhash.Compress(state, b); !bytes.Equal(ha, hb) || !bytes.Equal(ha, next) {
+		t.Errorf("Blocks don't collide: %x, %x, %x", ha, hb, next)
+	}
+	t.Logf("Found a collision in %d calls (expected about %.0f)", c.Calls, mdhash.CollisionWork(2))
+}
+
+func TestMulticollision(t *testing.T) {
+	c := NewCompressor(2)
+	m := c.Multicollision(mdhash.IV(2), 5)
+
+	if m.Len() != 32 {
+		t.Fatalf("Expected 32 messages, got %d", m.Len())
+	}
+
+	expected := mdhash.Sum(2, m.Message(0))
+	seen := make(map[string]bool)
+	for i := 0; i < m.Len(); i++ {
+		message := m.Message(i)
+		seen[string(message)] = true
+		if result := mdhash.Sum(2, message); !bytes.Equal(result, expected) {
+			t.Errorf("Message %d doesn't collide: %x != %x", i, result, expected)
+		}
+	}
+	if len(seen) != m.Len() {
+		t.Errorf("Expected %d unique messages, got %d", m.Len(), len(seen))
+	}
+
+	t.Logf("Found %d collisions in %d calls (expected about %.0f)", m.Len(), c.Calls, MulticollisionWork(2, 5))
+}
+
+func TestCascadeCollision(t *testing.T) {
+	f, g := NewCompressor(2), NewCompressor(3)
+
+	a, b, err := CascadeCollision(f, g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(a, b) {
+		t.Fatal("Messages are identical")
+	}
+
+	ha := CascadeSum(NewCompressor(2), NewCompressor(3), a)
+	hb := CascadeSum(NewCompressor(2), NewCompressor(3), b)
+	if !bytes.Equal(ha, hb) {
+		t.Errorf("Messages don't collide: %x != %x", ha, hb)
+	}
+	if !bytes.Equal(ha[:2], mdhash.Sum(2, a)) || !bytes.Equal(ha[2:], mdhash.Sum(3, a)) {
+		t.Errorf("Cascaded hash doesn't match mdhash: %x", ha)
+	}
+
+	fWork, gWork := CascadeWork(2, 3)
+	t.Logf("Calls to f: %d (expected about %.0f)", f.Calls, fWork)
+	t.Logf("Calls to g: %d (expected about %.0f)", g.Calls, gWork)
+}
+
+func TestCascadeCollisionOrder(t *testing.T) {
+	if _, _, err := CascadeCollision(NewCompressor(3), NewCompressor(2)); err == nil {
+		t.Error("Expected an error when f is more expensive than g")
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Kelsey and Schneier's Expandable Messages
+ *
+ * One of the basic yardsticks we use to judge a cryptographic hash function is
+ * its resistance to second preimage attacks. That means that if I give you x
+ * and y such that H(x) = y, you should have a tough time finding x' such that
+ * H(x') = H(x) = y.
+ *
+ * How tough? Brute-force tough. For a 2^b hash function, we want second
+ * preimage attacks to cost 2^b operations.
+ *
+ * This turns out not to be the case for very long messages.
+ *
+ * Consider the problem we're trying to solve: we want to find a message that
+ * will collide with H(x) in the very last invocation of the compression
+ * function. But an iterated hash has many intermediate states, and if we can
+ * collide with any one of them, we can follow the rest of the message from
+ * there.
+ *
+ * For a message of 2^k blocks, that's 2^k targets, so a single block from the
+ * right state will hit one of them after about 2^(b-k) tries.
+ *
+ * There's just one problem: Merkle-Damgard strengthening. The length of the
+ * message is included in the padding, so our forged message needs to be
+ * exactly as long as the original. Here's the trick: an expandable message.
+ *
+ * Expandable messages are built from a series of collisions between a
+ * single-block message and a message of 2^(k-i) + 1 blocks, for i from 1 to k:
+ *
+ *   1. Starting from the hash function's initial state, generate 2^(k-1)
+ *      dummy blocks and compute the state after them.
+ *   2. Find a single-block message that collides with a 2^(k-1) + 1 block
+ *      message, where the long message is the dummy blocks followed by one
+ *      more block.
+ *   3. From the state they collide in, do it again with 2^(k-2) dummy blocks,
+ *      and so on, down to 2^0.
+ *
+ * By picking either the short or the long message at each step, we can produce
+ * a message of any length from k to k + 2^k - 1 blocks, all of which leave the
+ * hash in the same final state.
+ *
+ * To forge a second preimage of a 2^k block message M:
+ *
+ *   1. Generate an expandable message for k.
+ *   2. Hash M and make a map of its intermediate states to block indices.
+ *   3. From the final state of the expandable message, find a single "bridge"
+ *      block that compresses to one of the intermediate states. Only states
+ *      within the range of lengths the expandable message can produce will
+ *      do.
+ *   4. Use the expandable message to produce a prefix of the right length,
+ *      so that the bridge block lands where the original block did.
+ *   5. Append the bridge block and the rest of M.
+ *
+ * The forgery is exactly as long as M, so it has the same padding and hashes
+ * to the same value.
+ */
+
+package set7
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/mdhash"
+)
+
+// A set of messages from k to k + 2^k - 1 blocks long which all leave the hash
+// in the same final state. Each step i is a collision between a single block
+// and a message of 2^(k-1-i) + 1 blocks.
+type ExpandableMessage struct {
+	Short [][]byte
+	Long  [][]byte
+	State []byte
+}
+
+// Generates an expandable message for lengths of k to k + 2^k - 1 blocks,
+// starting from `state`
+func (c *Compressor) ExpandableMessage(state []byte, k int) *ExpandableMessage {
+	e := &ExpandableMessage{State: state}
+	dummy := make([]byte, mdhash.BlockSize)
+
+	for i := k - 1; i >= 0; i-- {
+		// The dummy blocks only need to be hashed once per step
+		prefix := make([]byte, 0, (1<<uint(i))*mdhash.BlockSize)
+		for j := 0; j < 1<<uint(i); j++ {
+			prefix = append(prefix, dummy...)
+		}
+		longState := c.CompressBlocks(e.State, prefix)
+
+		short, last, next := c.findCollision(e.State, longState)
+		e.Short = append(e.Short, short)
+		e.Long = append(e.Long, append(prefix, last...))
+		e.State = next
+	}
+
+	return e
+}
+
+// The shortest and longest messages we can produce, in blocks
+func (e *ExpandableMessage) Bounds() (int, int) {
+	k := len(e.Short)
+	return k, k + (1 << uint(k)) - 1
+}
+
+// Produces a message which is exactly `blocks` blocks long
+func (e *ExpandableMessage) Produce(blocks int) ([]byte, error) {
+	min, max := e.Bounds()
+	if blocks < min || blocks > max {
+		return []byte{}, fmt.Errorf("Can't produce a message of %d blocks, must be between %d and %d", blocks, min, max)
+	}
+
+	// Each long message adds 2^(k-1-i) blocks to the shortest message, so the
+	// bits of the difference tell us which ones to use
+	extra := blocks - min
+	k := len(e.Short)
+	var message []byte
+	for i := range e.Short {
+		if extra&(1<<uint(k-1-i)) != 0 {
+			message = append(message, e.Long[i]...)
+		} else {
+			message = append(message, e.Short[i]...)
+		}
+	}
+	return message, nil
+}
+
+// Finds a second preimage of `message`, which must be at least k + 1 blocks
+// long, by bridging from an expandable message into one of its intermediate
+// states.
+func (c *Compressor) SecondPreimage(message []byte, k int) ([]byte, error) {
+	n := len(message) / mdhash.BlockSize
+	e := c.ExpandableMessage(mdhash.IV(c.Size), k)
+	min, max := e.Bounds()
+
+	// Map each usable intermediate state to the number of blocks before it.
+	// States after i blocks can be bridged to with a prefix of i - 1 blocks.
+	targets := make(map[string]int)
+	state := mdhash.IV(c.Size)
+	for i := 1; i <= n; i++ {
+		state = c.Compress(state, message[(i-1)*mdhash.BlockSize:i*mdhash.BlockSize])
+		if i-1 >= min && i-1 <= max {
+			targets[string(state)] = i
+		}
+	}
+	if len(targets) == 0 {
+		return []byte{}, errors.New("Message is too short for the expandable message")
+	}
+
+	for {
+		bridge := randomBlock()
+		i, ok := targets[string(c.Compress(e.State, bridge))]
+		if !ok {
+			continue
+		}
+
+		prefix, err := e.Produce(i - 1)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		forged := append(prefix, bridge...)
+		return append(forged, message[i*mdhash.BlockSize:]...), nil
+	}
+}
+
+// The number of compression function calls we expect a second preimage of a
+// 2^k block message to take with a state of `size` bytes: k collisions plus
+// the dummy blocks for the expandable message, and 2^(b-k) tries for the
+// bridge block.
+func SecondPreimageWork(size, k int) float64 {
+	dummies := math.Exp2(float64(k)) - 1
+	bridge := math.Exp2(float64(8*size - k))
+	return float64(k)*2*mdhash.CollisionWork(size) + dummies + bridge
+}
@@ -0,0 +1,77 @@
+package set7
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/mdhash"
+)
+
+func TestExpandableMessage(t *testing.T) {
+	c := NewCompressor(2)
+	e := c.ExpandableMessage(mdhash.IV(2), 4)
+
+	min, max := e.Bounds()
+	if min != 4 || max != 19 {
+		t.Fatalf("Expected bounds of 4 and 19, got %d and %d", min, max)
+	}
+
+	for blocks := min; blocks <= max; blocks++ {
+		message, err := e.Produce(blocks)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(message) != blocks*mdhash.BlockSize {
+			t.Errorf("Expected %d blocks, got %d bytes", blocks, len(message))
+		}
+		if state := NewCompressor(2).CompressBlocks(mdhash.IV(2), message); !bytes.Equal(state, e.State) {
+			t.Errorf("Message of %d blocks ends in state %x, expected %x", blocks, state, e.State)
+		}
+	}
+
+	if _, err := e.Produce(max + 1); err == nil {
+		t.Error("Expected an error for a message which is too long")
+	}
+	if _, err := e.Produce(min - 1); err == nil {
+		t.Error("Expected an error for a message which is too short")
+	}
+}
+
+func TestSecondPreimage(t *testing.T) {
+	const k = 10
+	size := 3
+
+	// 2^k blocks and a partial block
+	message, err := cryptopals.GenerateRandomBytes((1<<k)*mdhash.BlockSize + 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewCompressor(size)
+	forged, err := c.SecondPreimage(message, k)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Equal(forged, message) {
+		t.Fatal("Forged message is the same as the original")
+	}
+	if len(forged) != len(message) {
+		t.Errorf("Forged message is %d bytes, expected %d", len(forged), len(message))
+	}
+
+	expected := mdhash.Sum(size, message)
+	if result := mdhash.Sum(size, forged); !bytes.Equal(result, expected) {
+		t.Errorf("Hashes don't match: %x != %x", result, expected)
+	}
+
+	t.Logf("Found a second preimage in %d calls (expected about %.0f)", c.Calls, SecondPreimageWork(size, k))
+}
+
+func TestSecondPreimageShortMessage(t *testing.T) {
+	message := make([]byte, 4*mdhash.BlockSize)
+	if _, err := NewCompressor(2).SecondPreimage(message, 4); err == nil {
+		t.Error("Expected an error for a message shorter than the expandable message")
+	}
+}
@@ -0,0 +1,187 @@
+/*
+ * Kelsey and Kohno's Nostradamus Attack
+ *
+ * Hash functions are sometimes used as proof of a secret prediction.
+ *
+ * For example, suppose you wanted to predict the score of every Major League
+ * Baseball game in a season. (2,430 in all.) You might be concerned that
+ * publishing your predictions would affect the outcomes.
+ *
+ * So instead you write down all the scores, hash the document, and publish
+ * the hash. Once the season is over, you publish the document. Everyone can
+ * then hash the document to verify your soothsaying prowess.
+ *
+ * But what if you can't accurately predict the scores of 2.4k baseball games?
+ * Have no fear - forging this proof is easier than you think.
+ *
+ * Here's the idea:
+ *
+ *   1. Generate a large number of initial hash states. Say, 2^k.
+ *   2. Pair them up and generate single-block collisions. Now you have 2^k
+ *      hash states that collide into 2^(k-1) states.
+ *   3. Repeat the process. Pair up the 2^(k-1) states and generate
+ *      collisions. Now you have 2^(k-2) states.
+ *   4. Keep doing this until you have one state. This is your prediction.
+ *   5. Well, sort of. You need to commit to some length to encode in the
+ *      padding. Make sure it's long enough to accommodate your actual message,
+ *      this suffix, and a little bit of glue to join them up. Hash this
+ *      padding block using the state from step 4 - THIS is your prediction.
+ *
+ * What did you just build? It's basically a funnel mapping many initial
+ * states into a common final state. What's critical is we now have a big
+ * field of 2^k states we can try to collide into, but the actual suffix we'll
+ * add to the message is only k blocks long.
+ *
+ * The rest is trivial:
+ *
+ *   1. Wait until the end of the baseball season. (This may take some time.)
+ *   2. Write down the game results. Or, you know, anything else. I'm not too
+ *      particular.
+ *   3. Generate a random glue block and use it to hash the message. When you
+ *      find a collision with one of the 2^k leaves of the tree, you're done.
+ *   4. Follow the path from that leaf to the root and append the blocks from
+ *      the collisions along the way.
+ *
+ * How big should k be? Well, the work to build the tree is about k * 2^(b/2),
+ * and the work to find the glue block is about 2^(b-k). Balance the two.
+ */
+
+package set7
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/mdhash"
+)
+
+// The room we leave for predictions unless told otherwise, in blocks
+const defaultPrefixBlocks = 16
+
+// One step up the diamond structure: the block which takes a state to the
+// state it collides with at the next level
+type diamondLink struct {
+	block []byte
+	next  []byte
+}
+
+// A diamond structure of single block collisions which funnels 2^k leaf
+// states into a single final state
+type Diamond struct {
+	c *Compressor
+	// levels[0] links the leaves, levels[k-1] links into the final state
+	levels []map[string]diamondLink
+	State  []byte
+	// The number of blocks to leave for the prefix. Change it before calling
+	// Commit if predictions need more or less room.
+	PrefixBlocks int
+	// PrefixBlocks as it was when we committed. Herd only trusts this one,
+	// so changing PrefixBlocks later can't break it.
+	committedBlocks int
+	committed       bool
+}
+
+// Builds a diamond structure with 2^k leaves
+func (c *Compressor) BuildDiamond(k int) (*Diamond, error) {
+	if k < 1 {
+		return nil, errors.New("A diamond needs k >= 1")
+	}
+	d := &Diamond{c: c, PrefixBlocks: defaultPrefixBlocks}
+
+	// Distinct random leaf states
+	seen := make(map[string]bool)
+	var states [][]byte
+	for len(states) < 1<<uint(k) {
+		state := randomBlock()[:c.Size]
+		if !seen[string(state)] {
+			seen[string(state)] = true
+			states = append(states, state)
+		}
+	}
+
+	for len(states) > 1 {
+		level := make(map[string]diamondLink)
+		var next [][]byte
+		for i := 0; i < len(states); i += 2 {
+			a, b, h := c.findCollision(states[i], states[i+1])
+			level[string(states[i])] = diamondLink{a, h}
+			level[string(states[i+1])] = diamondLink{b, h}
+			next = append(next, h)
+		}
+		d.levels = append(d.levels, level)
+		states = next
+	}
+
+	d.State = states[0]
+	return d, nil
+}
+
+// The length in bytes of every message herded into the diamond: the prefix,
+// the linking block, and one block for each level of the diamond
+func (d *Diamond) length() uint64 {
+	return uint64((d.committedBlocks + 1 + len(d.levels)) * mdhash.BlockSize)
+}
+
+// The hash we commit to as our prediction. It's the final state of the
+// diamond with the padding for the committed length hashed into it. The
+// first call fixes the prefix length, so later calls return the same hash.
+func (d *Diamond) Commit() []byte {
+	if !d.committed {
+		d.committedBlocks = d.PrefixBlocks
+		d.committed = true
+	}
+	h := mdhash.NewExtension(d.State, d.length())
+	return h.Sum(nil)
+}
+
+// Forges a message which starts with `prefix` and hashes to the committed
+// value. The prefix is padded with spaces to the committed number of blocks,
+// then we search for a block which links it into one of the leaves and follow
+// the diamond to the final state.
+func (d *Diamond) Herd(prefix []byte) ([]byte, error) {
+	if !d.committed {
+		return []byte{}, errors.New("Commit to a prediction before herding")
+	}
+	size := d.committedBlocks * mdhash.BlockSize
+	if len(prefix) > size {
+		return []byte{}, fmt.Errorf("Prefix is longer than the committed %d bytes", size)
+	}
+
+	message := append([]byte{}, prefix...)
+	for len(message) < size {
+		message = append(message, ' ')
+	}
+
+	prefixState := d.c.CompressBlocks(mdhash.IV(d.c.Size), message)
+
+	var link, state []byte
+	for {
+		link = randomBlock()
+		state = d.c.Compress(prefixState, link)
+		if _, ok := d.levels[0][string(state)]; ok {
+			break
+		}
+	}
+	message = append(message, link...)
+
+	for _, level := range d.levels {
+		next, ok := level[string(state)]
+		if !ok {
+			return []byte{}, errors.New("State is missing from the diamond")
+		}
+		message = append(message, next.block...)
+		state = next.next
+	}
+
+	return message, nil
+}
+
+// The number of compression function calls we expect the attack to take with
+// a state of `size` bytes: 2^k - 1 collisions between two states to build the
+// diamond, and 2^(b-k) tries to find the linking block.
+func HerdWork(size, k int) (build, herd float64) {
+	build = (math.Exp2(float64(k)) - 1) * 2 * mdhash.CollisionWork(size)
+	herd = math.Exp2(float64(8*size - k))
+	return
+}
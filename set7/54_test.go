@@ -0,0 +1,101 @@
+package set7
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/mdhash"
+)
+
+const PREDICTION_54 = "Yankees 4, Red Sox 2\nMets 7, Phillies 3\n"
+
+func TestHerd(t *testing.T) {
+	const k = 6
+	size := 2
+
+	c := NewCompressor(size)
+	d, err := c.BuildDiamond(k)
+	if err != nil {
+		t.Fatal(err)
+	}
+	buildCalls := c.Calls
+	commitment := d.Commit()
+
+	forged, err := d.Herd([]byte(PREDICTION_54))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.HasPrefix(forged, []byte(PREDICTION_54)) {
+		t.Errorf("Forged message doesn't start with the prediction: %q", forged)
+	}
+	if result := mdhash.Sum(size, forged); !bytes.Equal(result, commitment) {
+		t.Errorf("Forged message doesn't match the commitment: %x != %x", result, commitment)
+	}
+
+	build, herd := HerdWork(size, k)
+	t.Logf("Built the diamond in %d calls (expected about %.0f)", buildCalls, build)
+	t.Logf("Herded the prediction in %d calls (expected about %.0f)", c.Calls-buildCalls, herd)
+
+	// The same diamond works for any other prediction
+	other := []byte("Cubs win the World Series")
+	forged, err = d.Herd(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := mdhash.Sum(size, forged); !bytes.Equal(result, commitment) {
+		t.Errorf("Second forged message doesn't match the commitment: %x != %x", result, commitment)
+	}
+}
+
+func TestHerdPrefixTooLong(t *testing.T) {
+	d, err := NewCompressor(2).BuildDiamond(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.PrefixBlocks = 0
+	d.Commit()
+	if _, err := d.Herd([]byte(PREDICTION_54)); err == nil {
+		t.Error("Expected an error for a prefix longer than the commitment")
+	}
+}
+
+func TestHerdBeforeCommit(t *testing.T) {
+	d, err := NewCompressor(2).BuildDiamond(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.Herd([]byte(PREDICTION_54)); err == nil {
+		t.Error("Expected an error for herding before committing")
+	}
+}
+
+// Changing PrefixBlocks after committing doesn't change the commitment
+func TestHerdIgnoresLaterPrefixBlocks(t *testing.T) {
+	c := NewCompressor(2)
+	d, err := c.BuildDiamond(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitment := d.Commit()
+
+	d.PrefixBlocks = 3
+	if again := d.Commit(); !bytes.Equal(again, commitment) {
+		t.Errorf("The commitment changed from %x to %x", commitment, again)
+	}
+	forged, err := d.Herd([]byte(PREDICTION_54))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := mdhash.Sum(2, forged); !bytes.Equal(result, commitment) {
+		t.Errorf("Forged message doesn't match the commitment: %x != %x", result, commitment)
+	}
+}
+
+func TestBuildDiamondInvalidK(t *testing.T) {
+	for _, k := range []int{0, -1} {
+		if _, err := NewCompressor(2).BuildDiamond(k); err == nil {
+			t.Errorf("Expected an error for k = %d", k)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+/*
+ * MD4 Collisions
+ *
+ * MD4 is a 128-bit cryptographic hash function, meaning it should take a
+ * work factor of roughly 2^64 to find collisions.
+ *
+ * It turns out we can do much better.
+ *
+ * The paper "Cryptanalysis of the Hash Functions MD4 and RIPEMD" by Wang et
+ * al details a cryptanalytic attack that lets us find collisions in 2^8 or
+ * less.
+ *
+ * Given a message block M, Wang outlines a strategy for finding a sister
+ * message block M', differing only in a few bits, that will collide with it.
+ * Just so long as a short set of conditions holds true for M.
+ *
+ * What sort of conditions? Simple bitwise equalities within the intermediate
+ * hash function state, e.g. a[1][6] = b[0][6]. This should be read as: "the
+ * sixth bit (zero-indexed) of a[1] (i.e. the first update to 'a') should
+ * equal the sixth bit of b[0] (i.e. the initial value of 'b')".
+ *
+ * It turns out that a lot of these conditions are trivial to enforce. To see
+ * why, take a look at the first (of three) rounds in the MD4 compression
+ * function. In this round, we iterate over each word in the message block
+ * sequentially and mix it into the state. So we can make sure all our
+ * first-round conditions hold by doing this:
+ *
+ *     # calculate the new value for a[1] in the normal fashion
+ *     a[1] = (a[0] + f(b[0], c[0], d[0]) + m[0]).lrot(3)
+ *
+ *     # correct the erroneous bit
+ *     a[1] ^= ((a[1][6] ^ b[0][6]) << 6)
+ *
+ *     # use algebra to correct the first message block
+ *     m[0] = a[1].rrot(3) - a[0] - f(b[0], c[0], d[0])
+ *
+ * Simply ensuring all the first round conditions puts us well within the
+ * range to generate collisions, but we can do better by correcting some
+ * additional conditions in the second round. This is a bit trickier, as we
+ * need to take care not to stomp on any of the first-round conditions.
+ *
+ * Once you've adequately massaged M, you can simply generate M' by flipping a
+ * few bits and test for a collision. A collision is not guaranteed as we
+ * didn't ensure every condition. But hopefully we got enough that we can find
+ * a suitable (M, M') pair without too much effort.
+ *
+ * Implement Wang's attack.
+ */
+
+package set7
+
+import (
+	"bytes"
+	"math/bits"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/md4"
+)
+
+type conditionKind int
+
+const (
+	bitZero conditionKind = iota
+	bitOne
+	bitEqual
+)
+
+// A condition on one bit (zero-indexed) of an intermediate MD4 state. Equal
+// conditions compare it with the same bit of the state `offset` steps before.
+type condition struct {
+	bit    uint
+	kind   conditionKind
+	offset int
+}
+
+func zero(bit uint) condition { return condition{bit, bitZero, 0} }
+func one(bit uint) condition  { return condition{bit, bitOne, 0} }
+
+// The state from the previous step
+func prev(bit uint) condition { return condition{bit, bitEqual, -1} }
+
+// The state from two steps before
+func prev2(bit uint) condition { return condition{bit, bitEqual, -2} }
+
+// The conditions on the state after each step of round 1, from Table 6 of
+// Wang et al:
+//
+//	a1, d1, c1, b1, a2, d2, c2, b2, a3, d3, c3, b3, a4, d4, c4, b4
+var round1Conditions = [16][]condition{
+	{prev(6)},
+	{zero(6), prev(7), prev(10)},
+	{one(6), one(7), zero(10), prev(25)},
+	{one(6), zero(7), zero(10), zero(25)},
+	{one(7), one(10), zero(25), prev(13)},
+	{zero(13), prev(18), prev(19), prev(20), prev(21), one(25)},
+	{prev(12), zero(13), prev(14), zero(18), zero(19), one(20), zero(21)},
+	{one(12), one(13), zero(14), prev(16), zero(18), zero(19), zero(20), zero(21)},
+	{one(12), one(13), one(14), zero(16), zero(18), zero(19), zero(20), one(21), prev(22), prev(25)},
+	{one(12), one(13), one(14), zero(16), zero(19), one(20), one(21), zero(22), one(25), prev(29)},
+	{one(16), zero(19), zero(20), zero(21), zero(22), zero(25), one(29), prev(31)},
+	{zero(19), one(20), one(21), prev(22), one(25), zero(29), zero(31)},
+	{zero(22), zero(25), prev(26), prev(28), one(29), zero(31)},
+	{zero(22), zero(25), one(26), one(28), zero(29), one(31)},
+	{prev(18), one(22), one(25), zero(26), zero(28), zero(29)},
+	{zero(18), one(25), one(26), one(28), zero(29)},
+}
+
+// The conditions on a5 and d5, the first two steps of round 2, which we can
+// correct without breaking the round 1 conditions
+var a5Conditions = []condition{prev2(18), one(25), zero(26), one(28), one(31)}
+var d5Conditions = []condition{prev(18), prev2(25), prev2(26), prev2(28), prev2(31)}
+
+// The MD4 state after each step, starting with the initial state. The
+// variable updated by step i is at i+4, with the other three inputs to the
+// step just before it:
+//
+//	a0, d0, c0, b0, a1, d1, c1, b1, a2, ...
+type md4States []uint32
+
+// Returns the value `c` requires for its bit of the state at `i`
+func (st md4States) want(i int, c condition) uint32 {
+	switch c.kind {
+	case bitZero:
+		return 0
+	case bitOne:
+		return 1
+	default:
+		return (st[i+c.offset] >> c.bit) & 1
+	}
+}
+
+// Returns the bits of the state at `i` which don't meet `conditions`
+func (st md4States) violations(i int, conditions []condition) uint32 {
+	var mask uint32
+	for _, c := range conditions {
+		if (st[i]>>c.bit)&1 != st.want(i, c) {
+			mask |= 1 << c.bit
+		}
+	}
+	return mask
+}
+
+// Solves step `i` of round 1 for the message word which produces the states
+// we have
+func (st md4States) round1Word(i int) uint32 {
+	return bits.RotateLeft32(st[i+4], -int(md4.Round1Shift(i))) - st[i] - md4.F(st[i+3], st[i+2], st[i+1])
+}
+
+func (st md4States) round2Step(i int, m [16]uint32) uint32 {
+	j := i + 16
+	return md4.Round2Step(st[j], st[j+3], st[j+2], st[j+1], m[md4.Round2Index(i)], md4.Round2Shift(i))
+}
+
+// Flips `mask` in the state after round 1 step `i`, and fixes up the message
+// words so that none of the other round 1 states change
+func (st md4States) flip(m *[16]uint32, i int, mask uint32) {
+	st[i+4] ^= mask
+	for j := i; j < i+5 && j < 16; j++ {
+		m[j] = st.round1Word(j)
+	}
+}
+
+// Massages the message block `m` so that it meets all of Wang's round 1
+// conditions, and as many of the a5 and d5 conditions as we can correct
+func WangMessageModification(m [16]uint32) [16]uint32 {
+	init := md4.InitialState()
+	st := make(md4States, 22)
+	st[0], st[1], st[2], st[3] = init[0], init[3], init[2], init[1]
+
+	// Round 1: compute each state normally, correct the bits which don't meet
+	// the conditions, and solve for the message word which produces it
+	for i := 0; i < 16; i++ {
+		st[i+4] = md4.Round1Step(st[i], st[i+3], st[i+2], st[i+1], m[i], md4.Round1Shift(i))
+		for _, c := range round1Conditions[i] {
+			st[i+4] &^= 1 << c.bit
+			st[i+4] |= st.want(i+4, c) << c.bit
+		}
+		m[i] = st.round1Word(i)
+	}
+
+	// a5 = (a4 + G(b4, c4, d4) + m0 + k) <<< 3
+	//
+	// Flipping bit i of a1 flips bit i-3 of m0, which flips bit i of a5.
+	// Then we correct m1 through m4 so that d1, c1, b1 and a2 don't change.
+	//
+	// A carry in the addition can flip other bits too, so we try a few times.
+	st[20] = st.round2Step(0, m)
+	for tries := 0; tries < 4; tries++ {
+		mask := st.violations(20, a5Conditions)
+		if mask == 0 {
+			break
+		}
+		st.flip(&m, 0, mask)
+		st[20] = st.round2Step(0, m)
+	}
+
+	// d5 = (d4 + G(a5, b4, c4) + m4 + k) <<< 5
+	//
+	// Flipping bit i-2 of a2 flips bit i-5 of m4, which flips bit i of d5.
+	// None of those bits of a2 have round 1 conditions. Then m5 through m8
+	// are corrected so d2, c2, b2 and a3 don't change.
+	st[21] = st.round2Step(1, m)
+	for tries := 0; tries < 4; tries++ {
+		mask := st.violations(21, d5Conditions)
+		if mask == 0 {
+			break
+		}
+		st.flip(&m, 4, bits.RotateLeft32(mask, -2))
+		st[21] = st.round2Step(1, m)
+	}
+
+	return m
+}
+
+// Returns M', which differs from M by
+//
+//	m1 + 2^31, m2 + 2^31 - 2^28, m12 - 2^16
+func WangDifferential(m [16]uint32) [16]uint32 {
+	m[1] += 1 << 31
+	m[2] += (1 << 31) - (1 << 28)
+	m[12] -= 1 << 16
+	return m
+}
+
+func md4Sum(message []byte) []byte {
+	h := md4.New()
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// Finds two different 64 byte messages with the same MD4 hash, and returns
+// the number of message blocks we tried
+func FindMD4Collision() ([]byte, []byte, int) {
+	for tries := 1; ; tries++ {
+		random, err := cryptopals.GenerateRandomBytes(64)
+		if err != nil {
+			panic(err)
+		}
+
+		m := WangMessageModification(md4.Words(random))
+		a, b := md4.Bytes(m), md4.Bytes(WangDifferential(m))
+
+		if bytes.Equal(md4Sum(a), md4Sum(b)) {
+			return a, b, tries
+		}
+	}
+}
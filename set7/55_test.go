@@ -0,0 +1,58 @@
+package set7
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/md4"
+)
+
+// Recomputes the states from round 1 and the first two steps of round 2
+func md4StatesFor(m [16]uint32) md4States {
+	init := md4.InitialState()
+	st := make(md4States, 22)
+	st[0], st[1], st[2], st[3] = init[0], init[3], init[2], init[1]
+	for i := 0; i < 16; i++ {
+		st[i+4] = md4.Round1Step(st[i], st[i+3], st[i+2], st[i+1], m[i], md4.Round1Shift(i))
+	}
+	st[20] = st.round2Step(0, m)
+	st[21] = st.round2Step(1, m)
+	return st
+}
+
+func TestWangMessageModification(t *testing.T) {
+	for n := 0; n < 100; n++ {
+		random, err := cryptopals.GenerateRandomBytes(64)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		st := md4StatesFor(WangMessageModification(md4.Words(random)))
+		for i, conditions := range round1Conditions {
+			if mask := st.violations(i+4, conditions); mask != 0 {
+				t.Fatalf("Round 1 step %d doesn't meet its conditions: %032b", i, mask)
+			}
+		}
+	}
+}
+
+func TestWordsRoundTrip(t *testing.T) {
+	random, _ := cryptopals.GenerateRandomBytes(64)
+	if result := md4.Bytes(md4.Words(random)); !bytes.Equal(result, random) {
+		t.Errorf("Expected %x, got %x", random, result)
+	}
+}
+
+func TestFindMD4Collision(t *testing.T) {
+	a, b, tries := FindMD4Collision()
+
+	if bytes.Equal(a, b) {
+		t.Fatal("Messages are identical")
+	}
+	if ha, hb := md4Sum(a), md4Sum(b); !bytes.Equal(ha, hb) {
+		t.Errorf("Messages don't collide: %x != %x", ha, hb)
+	}
+
+	t.Logf("Found a collision after %d tries:\n%x\n%x", tries, a, b)
+}
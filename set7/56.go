@@ -0,0 +1,183 @@
+/*
+ * RC4 Single-Byte Biases
+ *
+ * RC4 is popular stream cipher notable for its usage in protocols like TLS,
+ * WPA, RDP, &c.
+ *
+ * It's also susceptible to significant single-byte biases, especially early
+ * in the keystream. What does this mean?
+ *
+ * Simply: for a given position in the keystream, certain bytes are more (or
+ * less) likely to pop up than others. Given enough encryptions of a given
+ * plaintext, an attacker can use these biases to recover the entire
+ * plaintext.
+ *
+ * Now, search online for "On the Security of RC4 in TLS and WPA". This site
+ * is your one-stop shop for RC4 information.
+ *
+ * Click through to "Single-byte biases" on the left.
+ *
+ * Get comfortable with the analysis. Here's a plotted figure of the biases:
+ * it looks like the single-byte biases are real.
+ *
+ * Now, let's build an attack. Here's our oracle:
+ *
+ *     oracle(request) -> RC4(key, request || cookie)
+ *
+ * The cookie is this:
+ *
+ *     QkUgU1VSRSBUTyBEUklOSyBZT1VSIE9WQUxUSU5F
+ *
+ * Use a fresh 128-bit key on every invocation.
+ *
+ * Picture this scenario: you want to steal a user's secure cookie. You can
+ * spawn arbitrary requests (from a malicious plugin or somesuch) and monitor
+ * network traffic. (Ok, this is unrealistic - the cookie wouldn't be right
+ * next to the request, but let's pretend.)
+ *
+ * You can control the position of the cookie by requesting "/", "/A", "/AA",
+ * and so on.
+ *
+ * Build bias maps for a couple chosen indices (Z16 and Z32 are good) by
+ * generating encryptions with fresh keys. Then use the bias maps to discover
+ * the cookie's plaintext, byte by byte, by keeping a count of how often each
+ * byte shows up in those positions.
+ *
+ * The most common byte in Z16 is 240, and the most common byte in Z32 is
+ * 224. So the byte which most often appears at those positions in the
+ * ciphertext, XORed with 240 or 224, is most likely the byte of plaintext
+ * underneath.
+ *
+ * You'll need to run a lot of encryptions (around 2^24 per byte) to get
+ * reliable results. Consider using multiple processes.
+ */
+
+package set7
+
+import (
+	"sync"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+const COOKIE_56 = "QkUgU1VSRSBUTyBEUklOSyBZT1VSIE9WQUxUSU5F"
+
+// The most likely byte at keystream positions 16 and 32 (Z16 and Z32)
+const (
+	z16Bias = 240
+	z32Bias = 224
+)
+
+// Encrypts `request || cookie` with RC4 under a fresh key
+type RC4Oracle func(request []byte) []byte
+
+// Returns an oracle which encrypts requests followed by `cookie`
+func NewRC4CookieOracle(cookie []byte) RC4Oracle {
+	return func(request []byte) []byte {
+		key, err := cryptopals.GenerateRandomBytes(16)
+		if err != nil {
+			panic(err)
+		}
+		encrypted, err := cryptopals.RC4(append(append([]byte{}, request...), cookie...), key)
+		if err != nil {
+			panic(err)
+		}
+		return encrypted
+	}
+}
+
+// How much work to put into each byte of the cookie. Trials is the number of
+// oracle calls for each request length, split between Workers goroutines.
+type RC4BiasConfig struct {
+	Trials  int
+	Workers int
+}
+
+// How often each byte appeared at Z16 and Z32 in the ciphertexts
+type biasCounts [2][256]int
+
+// Encrypts `request` `trials` times and counts the ciphertext bytes at Z16
+// and Z32
+func countBiases(oracle RC4Oracle, request []byte, trials int) *biasCounts {
+	var counts biasCounts
+	for n := 0; n < trials; n++ {
+		c := oracle(request)
+		if len(c) >= 16 {
+			counts[0][c[15]]++
+		}
+		if len(c) >= 32 {
+			counts[1][c[31]]++
+		}
+	}
+	return &counts
+}
+
+// The byte which appeared most often, XORed with the bias for its position
+func mostLikelyPlaintext(counts [256]int, bias byte) byte {
+	best := 0
+	for b := range counts {
+		if counts[b] > counts[best] {
+			best = b
+		}
+	}
+	return byte(best) ^ bias
+}
+
+// Recovers the first `length` bytes (up to 32) of the cookie appended to
+// requests by the oracle, using the biases at Z16 and Z32.
+//
+// A request of n bytes puts cookie byte 15 - n at Z16 and byte 31 - n at Z32,
+// so each request length recovers two bytes of the cookie.
+func RecoverRC4Cookie(oracle RC4Oracle, length int, config RC4BiasConfig) []byte {
+	if config.Workers < 1 {
+		config.Workers = 1
+	}
+
+	cookie := make([]byte, length)
+
+	for n := 0; n < 16; n++ {
+		p16, p32 := 15-n, 31-n
+		if p16 >= length && p32 >= length {
+			continue
+		}
+
+		request := make([]byte, n)
+		for i := range request {
+			request[i] = 'A'
+		}
+
+		results := make(chan *biasCounts, config.Workers)
+		var wg sync.WaitGroup
+		for w := 0; w < config.Workers; w++ {
+			trials := config.Trials / config.Workers
+			if w < config.Trials%config.Workers {
+				trials++
+			}
+			wg.Add(1)
+			go func(trials int) {
+				defer wg.Done()
+				results <- countBiases(oracle, request, trials)
+			}(trials)
+		}
+		wg.Wait()
+		close(results)
+
+		var total biasCounts
+		for counts := range results {
+			for i := range total {
+				for b := range total[i] {
+					total[i][b] += counts[i][b]
+				}
+			}
+		}
+
+		if p16 < length {
+			cookie[p16] = mostLikelyPlaintext(total[0], z16Bias)
+		}
+		if p32 < length {
+			cookie[p32] = mostLikelyPlaintext(total[1], z32Bias)
+		}
+	}
+
+	return cookie
+}
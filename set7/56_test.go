@@ -0,0 +1,57 @@
+package set7
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestRC4CookieOracle(t *testing.T) {
+	cookie := []byte("cookie")
+	oracle := NewRC4CookieOracle(cookie)
+
+	a, b := oracle([]byte("/")), oracle([]byte("/"))
+	if len(a) != 7 {
+		t.Errorf("Expected 7 bytes of ciphertext, got %d", len(a))
+	}
+	if string(a) == string(b) {
+		t.Error("Oracle should use a fresh key on every call")
+	}
+}
+
+func TestMostLikelyPlaintext(t *testing.T) {
+	var counts [256]int
+	counts['B'^z16Bias] = 10
+	counts['A'^z16Bias] = 9
+	if result := mostLikelyPlaintext(counts, z16Bias); result != 'B' {
+		t.Errorf("Expected 'B', got %q", result)
+	}
+}
+
+// Recovering the whole cookie takes around 2^24 encryptions per byte, which
+// takes forever on one core, so this only recovers its first byte.
+func TestRecoverRC4CookiePrefix(t *testing.T) {
+	cookie, _ := base64.StdEncoding.DecodeString(COOKIE_56)
+
+	result := RecoverRC4Cookie(NewRC4CookieOracle(cookie), 1, RC4BiasConfig{
+		Trials:  1 << 23,
+		Workers: 4,
+	})
+	if string(result) != string(cookie[:1]) {
+		t.Errorf("Incorrect cookie.\nExpected:\t%q\nGot:\t\t%q", cookie[:1], result)
+	}
+}
+
+func TestRecoverRC4Cookie(t *testing.T) {
+	// This works, but it takes forever. Skip it.
+	t.Skip()
+
+	cookie, _ := base64.StdEncoding.DecodeString(COOKIE_56)
+
+	result := RecoverRC4Cookie(NewRC4CookieOracle(cookie), len(cookie), RC4BiasConfig{
+		Trials:  1 << 24,
+		Workers: 8,
+	})
+	if string(result) != string(cookie) {
+		t.Errorf("Incorrect cookie.\nExpected:\t%q\nGot:\t\t%q", cookie, result)
+	}
+}
@@ -0,0 +1,264 @@
+/*
+ * Diffie-Hellman Revisited: Small Subgroup Confinement
+ *
+ * This set is going to focus on elliptic curves. But before we get to that,
+ * we're going to kick things off with some classic Diffie-Hellman.
+ *
+ * Trust me, it's gonna make sense later.
+ *
+ * Let's get right into it. First, build your typical Diffie-Hellman key
+ * agreement: Alice and Bob exchange public keys and derive the same shared
+ * secret. Then Bob sends Alice some message with a MAC over it. Easy peasy.
+ *
+ * Use these parameters:
+ *
+ *     p = 7199773997391911030609999317773941274322764333428698921736339643928346453700085358802973900485592910475480089726140708102474957429903531369589969318716771
+ *     g = 4565356397095740655436854503483826832136106141639563487732438195343690437606117828318042418238184896212352329118608100083187535033402010599512641674644143
+ *
+ * The generator g has order q:
+ *
+ *     q = 236234353446506858198510045061214171961
+ *
+ * "Order" is a new word, but it just means g^q = 1 mod p. You might notice
+ * that q is a prime, just like p. This isn't mere chance: in fact, we chose q
+ * and p together such that q divides p-1 (the order or size of the group
+ * itself) evenly. This guarantees that an element g of order q will exist.
+ * (In fact, there will be q-1 such elements.)
+ *
+ * Back to the protocol. Alice and Bob should choose their secret keys as
+ * random integers mod q. There's no point in choosing them mod p; since g has
+ * order q, the numbers will just start repeating after that. You can prove
+ * this to yourself by verifying g^x mod p = g^(x + k*q) mod p for any x and
+ * k.
+ *
+ * The rest is the same as before.
+ *
+ * How can we attack this protocol? Remember what we said before about order:
+ * the fact that q divides p-1 guarantees the existence of elements of order q.
+ * What if there are smaller divisors of p-1?
+ *
+ * Spoiler alert: there are. I chose j = (p-1) / q to have many small factors
+ * because I want you to be happy. Find them by factoring j, which is:
+ *
+ *     j = 30477252323177606811760882179058908038824640750610513771646768011063128035873508507547741559514324673960576895059570
+ *
+ * You don't need to factor it all the way. Just find a bunch of factors
+ * smaller than, say, 2^16. There should be plenty. (Friendly tip: maybe
+ * avoid any repeated factors. They only complicate things.)
+ *
+ * Got 'em? Good. Now, we can use these to recover Bob's secret key using the
+ * Pohlig-Hellman algorithm for discrete logarithms. Here's how:
+ *
+ *   1. Take one of the small factors j. Call it r. We want to find an element
+ *      h of order r. To find it, do:
+ *
+ *          h := rand(1, p)^((p-1)/r) mod p
+ *
+ *      If h = 1, try again.
+ *
+ *   2. You're Eve. Send Bob h as your public key. Note that h is not a valid
+ *      public key! There is no x such that h = g^x mod p. But Bob doesn't
+ *      know that.
+ *
+ *   3. Bob will compute:
+ *
+ *          K := h^x mod p
+ *
+ *      Where x is his secret key and K is the output shared secret. Bob then
+ *      sends back (m, t), with:
+ *
+ *          m := "crazy flamboyant for the rap enjoyment"
+ *          t := MAC(K, m)
+ *
+ *   4. We (Eve) can't compute K, because h isn't actually a valid public key.
+ *      But we're not licked yet.
+ *
+ *      Remember how we saw that g^x starts repeating when x > q? h has the
+ *      same property with r. This means there are only r possible values of
+ *      K that Bob could have generated. We can recover K by doing a
+ *      brute-force search over these values until t = MAC(K, m).
+ *
+ *      Now we know Bob's secret key x mod r.
+ *
+ *   5. Repeat steps 1 through 4 many times. Eventually you will know:
+ *
+ *          x = b1 mod r1
+ *          x = b2 mod r2
+ *          x = b3 mod r3
+ *          ...
+ *
+ *      Once (r1*r2*...*rn) > q, you'll have enough information to
+ *      reassemble Bob's secret key using the Chinese Remainder Theorem.
+ */
+
+package set8
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+var big1 = big.NewInt(1)
+
+const MESSAGE_57 = "crazy flamboyant for the rap enjoyment"
+
+// The group from Challenge 57, where g generates a subgroup of prime order q
+func GetSubgroupParams() (*set_five.DHGroup, error) {
+	p, ok := new(big.Int).SetString("7199773997391911030609999317773941274322764333428698921736339643928346453700085358802973900485592910475480089726140708102474957429903531369589969318716771", 10)
+	if !ok {
+		return nil, errors.New("Error setting DH parameter p from string")
+	}
+	g, ok := new(big.Int).SetString("4565356397095740655436854503483826832136106141639563487732438195343690437606117828318042418238184896212352329118608100083187535033402010599512641674644143", 10)
+	if !ok {
+		return nil, errors.New("Error setting DH parameter g from string")
+	}
+	q, ok := new(big.Int).SetString("236234353446506858198510045061214171961", 10)
+	if !ok {
+		return nil, errors.New("Error setting DH parameter q from string")
+	}
+	return &set_five.DHGroup{P: p, G: g, Q: q}, nil
+}
+
+// A MAC over `message` with HMAC-SHA256, keyed with the shared secret `K`
+func MAC(K *big.Int, message []byte) []byte {
+	mac := hmac.New(sha256.New, K.Bytes())
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// Bob will complete a key exchange with anyone, and answers with a message
+// MACed under the shared secret. He never checks the public keys he's sent.
+type Bob struct {
+	session *set_five.DHSession
+}
+
+func NewBob(group *set_five.DHGroup) *Bob {
+	return &Bob{set_five.NewDHSessionWithGroup(group)}
+}
+
+func (b *Bob) PublicKey() *big.Int {
+	return b.session.PublicKey
+}
+
+// Derives a shared secret from `publicKey` and responds with a message and
+// its MAC
+func (b *Bob) Respond(publicKey *big.Int) (message, mac []byte) {
+	message = []byte(MESSAGE_57)
+	return message, MAC(b.session.SharedSecret(publicKey), message)
+}
+
+// Returns the distinct prime factors of `n` which are smaller than `bound`,
+// by trial division
+func SmallFactors(n *big.Int, bound int64) []*big.Int {
+	var factors []*big.Int
+	n = new(big.Int).Set(n)
+	m := new(big.Int)
+
+	for d := int64(2); d < bound; d++ {
+		r := big.NewInt(d)
+		if m.Mod(n, r).Sign() != 0 {
+			continue
+		}
+		factors = append(factors, r)
+		for m.Mod(n, r).Sign() == 0 {
+			n.Div(n, r)
+		}
+	}
+
+	return factors
+}
+
+// Finds a random element of order r mod p, where r is a prime factor of p-1:
+//
+//	h = rand(1, p)^((p-1)/r) mod p
+func ElementOfOrder(r, p *big.Int) (*big.Int, error) {
+	pMinus1 := new(big.Int).Sub(p, big1)
+	exp := new(big.Int).Div(pMinus1, r)
+
+	for {
+		random, err := rand.Int(rand.Reader, pMinus1)
+		if err != nil {
+			return nil, err
+		}
+		random.Add(random, big1)
+
+		h := random.Exp(random, exp, p)
+		if h.Cmp(big1) != 0 {
+			return h, nil
+		}
+	}
+}
+
+// Finds x mod r by trying every possible shared secret h^x until one of them
+// produces the same MAC
+func bruteForceMAC(h, r, p *big.Int, message, mac []byte) (*big.Int, error) {
+	K := big.NewInt(1)
+	for x := int64(0); x < r.Int64(); x++ {
+		if hmac.Equal(MAC(K, message), mac) {
+			return big.NewInt(x), nil
+		}
+		K.Mul(K, h)
+		K.Mod(K, p)
+	}
+	return nil, errors.New("No shared secret matches the MAC")
+}
+
+// Sends Bob public keys in small subgroups of order r for each small factor r
+// of j = (p-1)/q, and brute forces his private key mod r from the MACs he
+// sends back. Stops early once the product of the r's is larger than `enough`.
+//
+// Returns x mod r, where r is the product of the factors we used.
+func RecoverResidues(bob *Bob, group *set_five.DHGroup, enough *big.Int) (*big.Int, *big.Int, error) {
+	j := new(big.Int).Sub(group.P, big1)
+	j.Div(j, group.Q)
+
+	var residues, moduli []*big.Int
+	product := big.NewInt(1)
+
+	for _, r := range SmallFactors(j, 1<<16) {
+		h, err := ElementOfOrder(r, group.P)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		message, mac := bob.Respond(h)
+		x, err := bruteForceMAC(h, r, group.P, message, mac)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		residues = append(residues, x)
+		moduli = append(moduli, r)
+		product.Mul(product, r)
+
+		if product.Cmp(enough) > 0 {
+			break
+		}
+	}
+
+	if len(moduli) == 0 {
+		return nil, nil, errors.New("No small factors of j")
+	}
+
+	return cryptopals.CRT(residues, moduli)
+}
+
+// Recovers Bob's private key with the Pohlig-Hellman algorithm. Once the
+// product of the small factors is larger than q, the residues are enough to
+// reassemble the whole key with the CRT.
+func SubgroupConfinementAttack(bob *Bob, group *set_five.DHGroup) (*big.Int, error) {
+	x, r, err := RecoverResidues(bob, group, group.Q)
+	if err != nil {
+		return nil, err
+	}
+	if r.Cmp(group.Q) <= 0 {
+		return nil, errors.New("Not enough small factors to recover the key")
+	}
+	return x, nil
+}
@@ -0,0 +1,65 @@
+package set8
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestSmallFactors(t *testing.T) {
+	// 2^3 * 3 * 5^2 * 65537
+	n := big.NewInt(8 * 3 * 25 * 65537)
+	factors := SmallFactors(n, 1<<16)
+
+	expected := []int64{2, 3, 5}
+	if len(factors) != len(expected) {
+		t.Fatalf("Expected factors %v, got %v", expected, factors)
+	}
+	for i, f := range factors {
+		if f.Int64() != expected[i] {
+			t.Errorf("Expected factors %v, got %v", expected, factors)
+		}
+	}
+}
+
+func TestElementOfOrder(t *testing.T) {
+	group, err := GetSubgroupParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := big.NewInt(7963)
+	h, err := ElementOfOrder(r, group.P)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Exp(h, r, group.P).Cmp(big1) != 0 {
+		t.Errorf("h^r != 1 mod p")
+	}
+}
+
+func TestSubgroupParams(t *testing.T) {
+	group, err := GetSubgroupParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Exp(group.G, group.Q, group.P).Cmp(big1) != 0 {
+		t.Errorf("g does not have order q")
+	}
+}
+
+func TestSubgroupConfinementAttack(t *testing.T) {
+	group, err := GetSubgroupParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob := NewBob(group)
+	x, err := SubgroupConfinementAttack(bob, group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if y := new(big.Int).Exp(group.G, x, group.P); y.Cmp(bob.PublicKey()) != 0 {
+		t.Errorf("Recovered the wrong private key: %d", x)
+	}
+}
@@ -0,0 +1,282 @@
+/*
+ * Pollard's Method for Catching Kangaroos
+ *
+ * The last problem was a little contrived. It only worked because I
+ * helpfully foisted those broken group parameters on Alice and Bob. While
+ * real-world groups may include some small subgroups, it's unlikely to find
+ * this many in a randomly generated group.
+ *
+ * What if we can only recover some fraction of the Bob's secret key?
+ *
+ * Let's see how we can recover the rest. Here's a new group:
+ *
+ *     p = 11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623
+ *     q = 335062023296420808191071248367701059461
+ *     j = 34233586850807404623475048381328686211071196701374230492615844865929237417097514638999377942356150481334217896204702
+ *     g = 622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357
+ *
+ * Again, j = (p-1) / q, and g has order q. The only difference is that j has
+ * fewer small factors.
+ *
+ * We'll work around this by catching kangaroos. Pollard's kangaroo algorithm
+ * lets us find the index of y in the range [a, b]: the discrete log of y,
+ * provided it's in that range. It runs in about the square root of the size
+ * of the range.
+ *
+ * Here's the gist of it: we release a "tame" kangaroo from the end of the
+ * range, g^b, who makes N pseudorandom jumps, and we note where he stops. The
+ * jumps are chosen by a function f which maps group elements to jump
+ * distances, like f(y) = 2^(y mod k).
+ *
+ * Then we release a "wild" kangaroo from y, making jumps according to the
+ * same function. If he ever lands on the same spot as the tame kangaroo,
+ * he'll follow the same path from there on and fall into the trap. Since we
+ * know how far each of them jumped, we can work out where he started:
+ *
+ *     x = b + xT - xW
+ *
+ * If the wild kangaroo jumps past the trap without falling in, y isn't in
+ * the range, or we got unlucky.
+ *
+ * Implement Pollard's kangaroo algorithm. Check it with y = g^x for x in
+ * [0, 2^20] and [0, 2^40].
+ *
+ * Now, back to Bob. Use the subgroup confinement attack from the last
+ * problem to recover as much of his secret key as you can. You'll get a
+ * residue n modulo r, the product of the small factors of j:
+ *
+ *     x = n mod r
+ *
+ * This means x = n + m*r for some m, and we just need to find m. Since x < q,
+ * m must be in [0, (q-1)/r]. Transform y:
+ *
+ *     y' = y * g^-n = g^(m*r)
+ *     g' = g^r
+ *     y' = (g')^m
+ *
+ * Now catch m with the kangaroo algorithm, and reassemble x = n + m*r.
+ */
+
+package set8
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+// The group from Challenge 58, where j = (p-1)/q has fewer small factors
+func GetKangarooParams() (*set_five.DHGroup, error) {
+	p, ok := new(big.Int).SetString("11470374874925275658116663507232161402086650258453896274534991676898999262641581519101074740642369848233294239851519212341844337347119899874391456329785623", 10)
+	if !ok {
+		return nil, errors.New("Error setting DH parameter p from string")
+	}
+	g, ok := new(big.Int).SetString("622952335333961296978159266084741085889881358738459939978290179936063635566740258555167783009058567397963466103140082647486611657350811560630587013183357", 10)
+	if !ok {
+		return nil, errors.New("Error setting DH parameter g from string")
+	}
+	q, ok := new(big.Int).SetString("335062023296420808191071248367701059461", 10)
+	if !ok {
+		return nil, errors.New("Error setting DH parameter q from string")
+	}
+	return &set_five.DHGroup{P: p, G: g, Q: q}, nil
+}
+
+// Bob with a private key drawn from [min, max]
+func NewBobInRange(group *set_five.DHGroup, min, max *big.Int) *Bob {
+	return &Bob{set_five.NewDHSessionInRange(group, min, max)}
+}
+
+// The pseudorandom function the kangaroos use to decide how far to jump from
+// each group element. Pick chooses one of the jump Sizes for an element.
+type JumpFunction struct {
+	Sizes []*big.Int
+	Pick  func(y *big.Int) int
+}
+
+// f(y) = 2^(y mod k)
+func PowerOfTwoJumps(k int) *JumpFunction {
+	f := &JumpFunction{}
+	for i := 0; i < k; i++ {
+		f.Sizes = append(f.Sizes, new(big.Int).Lsh(big1, uint(i)))
+	}
+
+	K := big.NewInt(int64(k))
+	m := new(big.Int)
+	f.Pick = func(y *big.Int) int {
+		return int(m.Mod(y, K).Int64())
+	}
+	return f
+}
+
+// Picks k for f(y) = 2^(y mod k) so that the mean jump is about
+// sqrt(b - a) / 2, which is what Pollard suggests
+func DefaultJumps(a, b *big.Int) *JumpFunction {
+	target := new(big.Int).Sub(b, a)
+	target.Sqrt(target)
+	target.Rsh(target, 1)
+
+	k := 1
+	for {
+		// The mean of 2^0 ... 2^(k-1) is (2^k - 1) / k
+		mean := new(big.Int).Lsh(big1, uint(k))
+		mean.Sub(mean, big1)
+		mean.Div(mean, big.NewInt(int64(k)))
+		if mean.Cmp(target) >= 0 {
+			return PowerOfTwoJumps(k)
+		}
+		k++
+	}
+}
+
+// The mean of the jump sizes
+func (f *JumpFunction) mean() *big.Int {
+	sum := new(big.Int)
+	for _, size := range f.Sizes {
+		sum.Add(sum, size)
+	}
+	return sum.Div(sum, big.NewInt(int64(len(f.Sizes))))
+}
+
+// How many times we'll send the kangaroos out before giving up, and how far
+// we shift the range each time
+const (
+	kangarooTries = 5
+	kangarooShift = 12345
+)
+
+// What the kangaroos need to know about a cyclic group with generator g,
+// written multiplicatively. Elements are whatever the group uses.
+type KangarooGroup interface {
+	// g^x
+	Exp(x *big.Int) interface{}
+	Mul(a, b interface{}) interface{}
+	Equal(a, b interface{}) bool
+	// An integer for the jump function to pick a jump from
+	Hash(a interface{}) *big.Int
+}
+
+// The integers mod p, generated by g
+type modPGroup struct {
+	g, p *big.Int
+}
+
+func (m modPGroup) Exp(x *big.Int) interface{} { return new(big.Int).Exp(m.g, x, m.p) }
+
+func (m modPGroup) Mul(a, b interface{}) interface{} {
+	c := new(big.Int).Mul(a.(*big.Int), b.(*big.Int))
+	return c.Mod(c, m.p)
+}
+
+func (m modPGroup) Equal(a, b interface{}) bool { return a.(*big.Int).Cmp(b.(*big.Int)) == 0 }
+func (m modPGroup) Hash(a interface{}) *big.Int { return a.(*big.Int) }
+
+// Finds the discrete log of y = g^x mod p for x in [a, b], with jumps picked
+// by DefaultJumps. The wild kangaroo escapes now and then even when x is in
+// range, so we try again on a shifted range before giving up.
+func Kangaroo(g, y, p, a, b *big.Int) (*big.Int, error) {
+	return KangarooWithRetries(modPGroup{g, p}, y, a, b)
+}
+
+// Finds the discrete log of y = g^x mod p for x in [a, b] with a single run
+// of Pollard's kangaroo algorithm, using `f` to pick the jumps
+func KangarooWithJumps(g, y, p, a, b *big.Int, f *JumpFunction) (*big.Int, error) {
+	return KangarooInGroup(modPGroup{g, p}, y, a, b, f)
+}
+
+// Runs KangarooInGroup with DefaultJumps, and when the wild kangaroo
+// escapes, tries again with y*g^s in [a+s, b+s]. Shifting x puts both
+// kangaroos on new paths.
+func KangarooWithRetries(group KangarooGroup, y interface{}, a, b *big.Int) (*big.Int, error) {
+	f := DefaultJumps(a, b)
+
+	var err error
+	for i := int64(0); i < kangarooTries; i++ {
+		s := big.NewInt(i * kangarooShift)
+		ys := group.Mul(y, group.Exp(s))
+
+		var x *big.Int
+		x, err = KangarooInGroup(group, ys, new(big.Int).Add(a, s), new(big.Int).Add(b, s), f)
+		if err == nil {
+			return x.Sub(x, s), nil
+		}
+	}
+	return nil, err
+}
+
+// Finds the discrete log of y = g^x for x in [a, b] with Pollard's kangaroo
+// algorithm, using `f` to pick the jumps
+func KangarooInGroup(group KangarooGroup, y interface{}, a, b *big.Int, f *JumpFunction) (*big.Int, error) {
+	// g^f(y) for each jump size, so each jump is a single multiplication
+	powers := make([]interface{}, len(f.Sizes))
+	for i, size := range f.Sizes {
+		powers[i] = group.Exp(size)
+	}
+
+	// N = 4 * mean(f)
+	N := new(big.Int).Mul(f.mean(), big.NewInt(4))
+
+	// The tame kangaroo starts at g^b and makes N jumps
+	xT := new(big.Int)
+	yT := group.Exp(b)
+	for i := new(big.Int); i.Cmp(N) < 0; i.Add(i, big1) {
+		j := f.Pick(group.Hash(yT))
+		xT.Add(xT, f.Sizes[j])
+		yT = group.Mul(yT, powers[j])
+	}
+
+	// The wild kangaroo starts at y, and has passed the trap once he's jumped
+	// further than b - a + xT
+	limit := new(big.Int).Sub(b, a)
+	limit.Add(limit, xT)
+
+	xW := new(big.Int)
+	yW := y
+	for xW.Cmp(limit) < 0 {
+		j := f.Pick(group.Hash(yW))
+		xW.Add(xW, f.Sizes[j])
+		yW = group.Mul(yW, powers[j])
+
+		if group.Equal(yW, yT) {
+			// x = b + xT - xW
+			x := new(big.Int).Add(b, xT)
+			return x.Sub(x, xW), nil
+		}
+	}
+
+	return nil, errors.New("The wild kangaroo escaped")
+}
+
+// Recovers Bob's private key when j only has enough small factors to give us
+// part of it. The subgroup confinement attack gives us x = n mod r, and we
+// catch the rest, m = (x - n) / r, with the kangaroo algorithm.
+func KangarooAttack(bob *Bob, group *set_five.DHGroup) (*big.Int, error) {
+	p, g := group.P, group.G
+
+	n, r, err := RecoverResidues(bob, group, group.Q)
+	if err != nil {
+		return nil, err
+	}
+
+	// y' = y * g^-n
+	gn := new(big.Int).Exp(g, n, p)
+	y := new(big.Int).Mul(bob.PublicKey(), gn.ModInverse(gn, p))
+	y.Mod(y, p)
+
+	// g' = g^r
+	gr := new(big.Int).Exp(g, r, p)
+
+	// m is in [0, (q-1)/r]
+	max := new(big.Int).Sub(group.Q, big1)
+	max.Div(max, r)
+
+	m, err := Kangaroo(gr, y, p, new(big.Int), max)
+	if err != nil {
+		return nil, err
+	}
+
+	// x = n + m*r
+	x := m.Mul(m, r)
+	return x.Add(x, n), nil
+}
@@ -0,0 +1,80 @@
+package set8
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestKangarooParams(t *testing.T) {
+	group, err := GetKangarooParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).Exp(group.G, group.Q, group.P).Cmp(big1) != 0 {
+		t.Errorf("g does not have order q")
+	}
+}
+
+func TestKangaroo(t *testing.T) {
+	group, err := GetKangarooParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a, b := big.NewInt(0), big.NewInt(1<<20)
+	bob := NewBobInRange(group, a, b)
+
+	x, err := Kangaroo(group.G, bob.PublicKey(), group.P, a, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y := new(big.Int).Exp(group.G, x, group.P); y.Cmp(bob.PublicKey()) != 0 {
+		t.Errorf("Recovered the wrong private key: %d", x)
+	}
+}
+
+func TestKangarooOffsetRange(t *testing.T) {
+	group, err := GetKangarooParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := big.NewInt(123456789)
+	y := new(big.Int).Exp(group.G, x, group.P)
+
+	result, err := KangarooWithJumps(group.G, y, group.P, big.NewInt(123400000), big.NewInt(123500000), PowerOfTwoJumps(9))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(x) != 0 {
+		t.Errorf("Expected %d, got %d", x, result)
+	}
+}
+
+func TestKangarooOutOfRange(t *testing.T) {
+	group, err := GetKangarooParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	y := new(big.Int).Exp(group.G, big.NewInt(1<<30), group.P)
+	if _, err := Kangaroo(group.G, y, group.P, big.NewInt(0), big.NewInt(1<<16)); err == nil {
+		t.Error("Expected the kangaroo to escape when x isn't in the range")
+	}
+}
+
+func TestKangarooAttack(t *testing.T) {
+	group, err := GetKangarooParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bob := NewBob(group)
+	x, err := KangarooAttack(bob, group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y := new(big.Int).Exp(group.G, x, group.P); y.Cmp(bob.PublicKey()) != 0 {
+		t.Errorf("Recovered the wrong private key: %d", x)
+	}
+}
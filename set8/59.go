@@ -0,0 +1,273 @@
+/*
+ * Elliptic Curve Diffie-Hellman and Invalid-Curve Attacks
+ *
+ * I'm not going to show you any graphs - if you want to see one, you can
+ * find them in, like, every other elliptic curve tutorial on the internet.
+ * Personally, I've never been able to gain much insight from them.
+ *
+ * They're also really hard to draw in ASCII.
+ *
+ * The key thing to understand about elliptic curves is that they're a setting
+ * analogous in many ways to one we're more familiar with, the multiplicative
+ * integers mod p. So if we learn how certain primitive operations are
+ * defined, we can reason about them using a lot of tools we already have in
+ * our utility belts.
+ *
+ * Let's get into it. An elliptic curve E is just an equation like this:
+ *
+ *     y^2 = x^3 + a*x + b
+ *
+ * The choice of the a and b coefficients defines the curve.
+ *
+ * The elements in our group are going to be (x, y) coordinates satisfying
+ * the curve equation. Now, there are infinitely many pairs like that on the
+ * curve, but we only want to think about some of them. We'll trim our set of
+ * points down by considering the curve in the context of a finite field.
+ *
+ * For the moment, it's not too important to know what a finite field is. You
+ * can basically just think of it as "integers mod p" with all the usual
+ * operations you expect: multiplication, division (via modular inversion),
+ * addition, and subtraction.
+ *
+ * We'll also need an identity element, the point at infinity O, and a group
+ * operation: point addition with the chord and tangent rule.
+ *
+ * Our curve is:
+ *
+ *     y^2 = x^3 - 95051*x + 11279326
+ *
+ * over GF(233970423115425145524320034830162017933). Use the base point:
+ *
+ *     (182, 85518893674295321206118380980485522083)
+ *
+ * which has order 29246302889428143187362802287225875743. The order of the
+ * curve itself is 233970423115425145498902418297807005944, which is 8 times
+ * the order of the base point.
+ *
+ * Implement ECDH and verify that you can do a handshake correctly. In this
+ * case, Alice and Bob's secrets will be scalars modulo the base point order
+ * and their public elements will be points. If you implemented the group
+ * operation correctly, you should be able to confirm that Alice and Bob
+ * derive the same shared secret.
+ *
+ * Let's start with the attack. Remember the subgroup confinement attack from
+ * Challenge 57? The order of our curve has some small factors - but only 2^3.
+ * Not very promising.
+ *
+ * But notice that the formulas for point addition and doubling never use b.
+ * Bob will happily compute his shared secret using a point from an entirely
+ * different curve, as long as it has the same a. Pick a different b and you
+ * get a different curve with a different order. For example:
+ *
+ *     y^2 = x^3 - 95051*x + 210
+ *     y^2 = x^3 - 95051*x + 504
+ *     y^2 = x^3 - 95051*x + 727
+ *
+ * with orders:
+ *
+ *     233970423115425145550826547352470124412
+ *     233970423115425145544350131142039591210
+ *     233970423115425145545378039958152057148
+ *
+ * They should have a fair few small factors between them. So: find some
+ * points of small order and send them to Bob. You can use exactly the same
+ * strategy as before to recover his secret key mod each small factor. Then
+ * use the CRT to put it back together.
+ *
+ * To find a point of order r, pick a random point on one of the bogus curves
+ * and multiply it by the curve's order divided by r. If you get the point at
+ * infinity, try again.
+ */
+
+package set8
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"net"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+)
+
+// A curve with the same a and p as the challenge curve, which we can slip
+// points from past Bob, and the number of points on it
+type InvalidCurve struct {
+	B     *big.Int
+	Order *big.Int
+}
+
+func setString(s string) (*big.Int, error) {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return nil, errors.New("Error setting curve parameter from string: " + s)
+	}
+	return n, nil
+}
+
+// The curve and base point from Challenge 59:
+//
+//	y^2 = x^3 - 95051*x + 11279326
+func GetECParams() (*ec.Group, error) {
+	var values []*big.Int
+	for _, s := range []string{
+		"233970423115425145524320034830162017933",
+		"85518893674295321206118380980485522083",
+		"29246302889428143187362802287225875743",
+	} {
+		n, err := setString(s)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, n)
+	}
+	p, gy, n := values[0], values[1], values[2]
+
+	curve := &ec.Curve{A: big.NewInt(-95051), B: big.NewInt(11279326), P: p}
+	return &ec.Group{Curve: curve, G: ec.NewPoint(big.NewInt(182), gy), N: n}, nil
+}
+
+// The curves from Challenge 59 with b = 210, 504 and 727
+func GetInvalidCurves() ([]InvalidCurve, error) {
+	bs := []int64{210, 504, 727}
+	orders := []string{
+		"233970423115425145550826547352470124412",
+		"233970423115425145544350131142039591210",
+		"233970423115425145545378039958152057148",
+	}
+
+	var curves []InvalidCurve
+	for i, b := range bs {
+		n, err := setString(orders[i])
+		if err != nil {
+			return nil, err
+		}
+		curves = append(curves, InvalidCurve{big.NewInt(b), n})
+	}
+	return curves, nil
+}
+
+// A MAC over `message` with HMAC-SHA256, keyed with both coordinates of the
+// shared secret `K`
+func ECMAC(K *ec.Point, message []byte) []byte {
+	var key []byte
+	if !K.IsInfinity() {
+		key = append(K.X.Bytes(), K.Y.Bytes()...)
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(message)
+	return mac.Sum(nil)
+}
+
+// Bob over elliptic curves. He completes an ECDH exchange with anyone who
+// connects, and answers with a message MACed under the shared secret, without
+// checking that their public key is on his curve.
+type ECBob struct {
+	key *ec.PrivateKey
+}
+
+func NewECBob(group *ec.Group) (*ECBob, error) {
+	key, err := ec.GenerateKey(group)
+	if err != nil {
+		return nil, err
+	}
+	return &ECBob{key}, nil
+}
+
+func (b *ECBob) PublicKey() *ec.Point {
+	return b.key.Public
+}
+
+// Handles one ECDH exchange over `conn`: read the client's public key, send
+// ours back, then send the message and its MAC
+func (b *ECBob) Handler(conn net.Conn) error {
+	server := set_five.NewDHClient("Bob", conn, nil)
+	e := server.ReadECDHE()
+
+	K := b.key.SharedSecret(e.PublicKey)
+	server.Send(set_five.ECDHExchange{Group: b.key.Group, PublicKey: b.key.Public})
+
+	message := []byte(MESSAGE_57)
+	server.Send(message)
+	server.Send(ECMAC(K, message))
+	return nil
+}
+
+// Connects to Bob over `conn` and sends him `publicKey`. Returns Bob's public
+// key along with the message and MAC he sent.
+func ExchangeECDH(conn net.Conn, group *ec.Group, publicKey *ec.Point) (*ec.Point, []byte, []byte) {
+	client := set_five.NewDHClient("Alice", conn, nil)
+	client.Send(set_five.ECDHExchange{Group: group, PublicKey: publicKey})
+
+	bob := client.ReadECDHE()
+	message := client.ReadMessage(set_five.TCP_BYTES).([]byte)
+	mac := client.ReadMessage(set_five.TCP_BYTES).([]byte)
+	return bob.PublicKey, message, mac
+}
+
+// Finds x mod r by trying every possible shared secret x*h until one of them
+// produces the same MAC
+func bruteForceECMAC(curve *ec.Curve, h *ec.Point, r *big.Int, message, mac []byte) (*big.Int, error) {
+	K := ec.Infinity()
+	for x := int64(0); x < r.Int64(); x++ {
+		if hmac.Equal(ECMAC(K, message), mac) {
+			return big.NewInt(x), nil
+		}
+		K = curve.Add(K, h)
+	}
+	return nil, errors.New("No shared secret matches the MAC")
+}
+
+// Recovers Bob's private key by sending him points of small order from
+// invalid curves. `dial` opens a new connection to Bob for each exchange.
+//
+// Each point of order r gives us his key mod r, and the residues from all of
+// the curves are combined with the generalized CRT, since small factors like
+// 2 turn up in the order of more than one curve.
+func InvalidCurveAttack(dial func() (net.Conn, error), group *ec.Group, curves []InvalidCurve) (*big.Int, error) {
+	var residues, moduli []*big.Int
+
+	for _, invalid := range curves {
+		curve := group.Curve.WithB(invalid.B)
+
+		for _, r := range SmallFactors(invalid.Order, 1<<16) {
+			// Skip repeated factors. The r-torsion might not be cyclic, in
+			// which case multiplying by order/r always gives the identity.
+			if new(big.Int).Mod(invalid.Order, new(big.Int).Mul(r, r)).Sign() == 0 {
+				continue
+			}
+
+			h, err := curve.PointOfOrder(r, invalid.Order)
+			if err != nil {
+				return nil, err
+			}
+
+			conn, err := dial()
+			if err != nil {
+				return nil, err
+			}
+			_, message, mac := ExchangeECDH(conn, group, h)
+			conn.Close()
+
+			x, err := bruteForceECMAC(curve, h, r, message, mac)
+			if err != nil {
+				return nil, err
+			}
+			residues = append(residues, x)
+			moduli = append(moduli, r)
+
+			x, N, err := cryptopals.GeneralizedCRT(residues, moduli)
+			if err != nil {
+				return nil, err
+			}
+			if N.Cmp(group.N) > 0 {
+				return x, nil
+			}
+		}
+	}
+
+	return nil, errors.New("Not enough small factors to recover the key")
+}
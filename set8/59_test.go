@@ -0,0 +1,105 @@
+package set8
+
+import (
+	"bytes"
+	"net"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+)
+
+// Dials a Bob who handles each connection over an in-memory pipe
+func pipeTo(handler func(net.Conn) error) func() (net.Conn, error) {
+	return func() (net.Conn, error) {
+		client, server := net.Pipe()
+		go func() {
+			defer server.Close()
+			handler(server)
+		}()
+		return client, nil
+	}
+}
+
+func TestECParams(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !group.Curve.IsOnCurve(group.G) {
+		t.Errorf("The base point isn't on the curve")
+	}
+	if !group.Curve.ScalarMult(group.G, group.N).IsInfinity() {
+		t.Errorf("The base point does not have order n")
+	}
+}
+
+func TestInvalidCurveOrders(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	curves, err := GetInvalidCurves()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, invalid := range curves {
+		curve := group.Curve.WithB(invalid.B)
+		p, err := curve.RandomPoint()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !curve.ScalarMult(p, invalid.Order).IsInfinity() {
+			t.Errorf("Wrong order for the curve with b = %d", invalid.B)
+		}
+	}
+}
+
+func TestECDHHandshake(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewECBob(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := ec.GenerateKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, _ := pipeTo(bob.Handler)()
+	defer conn.Close()
+
+	bobPublic, message, mac := ExchangeECDH(conn, group, alice.Public)
+	if !bobPublic.Equal(bob.PublicKey()) {
+		t.Errorf("Bob sent the wrong public key")
+	}
+	if !bytes.Equal(ECMAC(alice.SharedSecret(bobPublic), message), mac) {
+		t.Errorf("Alice and Bob derived different shared secrets")
+	}
+}
+
+func TestInvalidCurveAttack(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	curves, err := GetInvalidCurves()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewECBob(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := InvalidCurveAttack(pipeTo(bob.Handler), group, curves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !group.Curve.ScalarMult(group.G, x).Equal(bob.PublicKey()) {
+		t.Errorf("Recovered the wrong private key: %d", x)
+	}
+}
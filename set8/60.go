@@ -0,0 +1,370 @@
+/*
+ * Single-Coordinate Ladders and Insecure Twists
+ *
+ * All our hard work is about to pay some dividends. Here's a list of
+ * cool-kids jargon you'll be able to deploy after completing this challenge:
+ *
+ *   - Montgomery curve
+ *   - single-coordinate ladder
+ *   - isomorphism
+ *   - birational equivalence
+ *   - quadratic twist
+ *   - twist security
+ *
+ * Remember that the B parameter of a curve doesn't affect the group law, so
+ * the attack from the last problem relies on Bob not checking that the points
+ * he's sent are on his curve. There's a popular alternative: represent points
+ * by their x coordinate alone. Then you can't send Bob a point off the curve,
+ * because there's no y coordinate to mismatch.
+ *
+ * Let's use a Montgomery curve:
+ *
+ *     v^2 = u^3 + 534*u^2 + u
+ *
+ * over the same field as before, which is birationally equivalent to our
+ * Weierstrass curve from Challenge 59:
+ *
+ *     u = x - 178
+ *     v = y
+ *
+ * The base point is u = 4, which has the same order as before. Implement the
+ * Montgomery ladder:
+ *
+ *     function ladder(u, k):
+ *         u2, w2 := (1, 0)
+ *         u3, w3 := (u, 1)
+ *         for i in reverse(range(bitlen(p))):
+ *             b := 1 & (k >> i)
+ *             u2, u3 := cswap(u2, u3, b)
+ *             w2, w3 := cswap(w2, w3, b)
+ *             u3, w3 := ((u2*u3 - w2*w3)^2,
+ *                        u * (u2*w3 - w2*u3)^2)
+ *             u2, w2 := ((u2^2 - w2^2)^2,
+ *                        4*u2*w2 * (u2^2 + A*u2*w2 + w2^2))
+ *             u2, u3 := cswap(u2, u3, b)
+ *             w2, w3 := cswap(w2, w3, b)
+ *         return u2 * w2^(p-2)
+ *
+ * and check that ladder(4, n) = 0.
+ *
+ * Now here's the thing: any u we hand Bob is either on the curve, or on its
+ * quadratic twist. The ladder doesn't care which. The twist has order
+ * 2*p + 2 - (order of the curve), which is:
+ *
+ *     233970423115425145549737651362517029924
+ *
+ * and it has a bunch of small factors. So find points of small order r on
+ * the twist and send them to Bob, just like before. You'll recover his
+ * secret mod r, but only up to sign: x and -x give the same u coordinate,
+ * so all you learn is x = +-b mod r.
+ *
+ * The small factors don't quite get you all the way to n, either. Once you
+ * run out, use the kangaroo algorithm from Challenge 58 to recover the rest.
+ * You'll need to do it in the curve group, and since the kangaroos need to
+ * add points, you'll want the Weierstrass form of the curve.
+ */
+
+package set8
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+)
+
+// The largest twist factor we'll brute force. The twist order's next factor
+// is over 2^40, which is far more work than the kangaroos.
+const twistFactorBound = 1 << 24
+
+// A Montgomery curve with base point U of order N. Order is the number of
+// points on the curve.
+type MontgomeryGroup struct {
+	Curve *ec.MontgomeryCurve
+	U     *big.Int
+	N     *big.Int
+	Order *big.Int
+}
+
+// The curve from Challenge 60:
+//
+//	v^2 = u^3 + 534*u^2 + u
+func GetMontgomeryParams() (*MontgomeryGroup, error) {
+	group, err := GetECParams()
+	if err != nil {
+		return nil, err
+	}
+	order, err := setString("233970423115425145498902418297807005944")
+	if err != nil {
+		return nil, err
+	}
+
+	curve := &ec.MontgomeryCurve{A: big.NewInt(534), B: big.NewInt(1), P: group.Curve.P}
+	return &MontgomeryGroup{curve, big.NewInt(4), group.N, order}, nil
+}
+
+// The number of points on the quadratic twist, 2*p + 2 - the order of the
+// curve
+func (g *MontgomeryGroup) TwistOrder() *big.Int {
+	n := new(big.Int).Add(g.Curve.P, big1)
+	n.Lsh(n, 1)
+	return n.Sub(n, g.Order)
+}
+
+// Bob with a single-coordinate ladder. Public keys are u coordinates, and he
+// never checks whether the ones he's sent are on the curve or the twist.
+type LadderBob struct {
+	group  *MontgomeryGroup
+	key    *big.Int
+	public *big.Int
+}
+
+func NewLadderBob(group *MontgomeryGroup) (*LadderBob, error) {
+	// A private key in [1, n)
+	key, err := rand.Int(rand.Reader, new(big.Int).Sub(group.N, big1))
+	if err != nil {
+		return nil, err
+	}
+	key.Add(key, big1)
+	return &LadderBob{group, key, group.Curve.Ladder(group.U, key)}, nil
+}
+
+func (b *LadderBob) PublicKey() *big.Int {
+	return b.public
+}
+
+// Derives a shared secret from the u coordinate `publicKey`, and responds
+// with a message and its MAC
+func (b *LadderBob) Respond(publicKey *big.Int) (message, mac []byte) {
+	message = []byte(MESSAGE_57)
+	return message, MAC(b.group.Curve.Ladder(publicKey, b.key), message)
+}
+
+// Finds a random point on the twist, which has `order` points, whose order
+// is the product of the distinct primes `factors`
+func twistPointOfOrder(curve *ec.MontgomeryCurve, order *big.Int, factors ...*big.Int) (*big.Int, error) {
+	r := big.NewInt(1)
+	for _, f := range factors {
+		r.Mul(r, f)
+	}
+	cofactor := new(big.Int).Div(order, r)
+
+	for {
+		u, err := curve.RandomTwistPoint()
+		if err != nil {
+			return nil, err
+		}
+		h := curve.Ladder(u, cofactor)
+
+		// h has order r if (r/f)*h isn't the identity for any of the factors
+		found := true
+		for _, f := range factors {
+			if curve.Ladder(h, new(big.Int).Div(r, f)).Sign() == 0 {
+				found = false
+				break
+			}
+		}
+		if found {
+			return h, nil
+		}
+	}
+}
+
+// Finds b where x = +-b mod r, by stepping through the multiples of the
+// point h with differential additions until one of them produces the same
+// MAC. Since u(k*h) = u(-k*h), we only need to go halfway.
+func bruteForceLadderMAC(curve *ec.MontgomeryCurve, h, r *big.Int, message, mac []byte) (*big.Int, error) {
+	base := ec.NewXPoint(h)
+
+	// prev = (k-1)*h, kh = k*h
+	var prev ec.XPoint
+	kh := ec.XPoint{U: big.NewInt(1), W: big.NewInt(0)}
+	for k := int64(0); k <= r.Int64()/2; k++ {
+		if hmac.Equal(MAC(curve.Affine(kh), message), mac) {
+			return big.NewInt(k), nil
+		}
+
+		switch k {
+		case 0:
+			prev, kh = kh, base
+		case 1:
+			prev, kh = kh, curve.XDouble(kh)
+		default:
+			prev, kh = kh, curve.XAdd(kh, base, prev)
+		}
+	}
+
+	return nil, errors.New("No shared secret matches the MAC")
+}
+
+// Sends Bob points of small order from the twist for each small factor r of
+// the twist order, and brute forces his private key mod r, up to sign.
+//
+// Then the signs are lined up by sending points of order r0 * ri for a
+// reference factor r0: only one of CRT(b0, bi) and CRT(b0, -bi) gives Bob's
+// MAC. Zero residues don't have a sign, so they're left alone.
+//
+// Returns n and R, the product of the factors, where x = +-n mod R.
+func RecoverTwistResidues(bob *LadderBob, group *MontgomeryGroup, bound int64) (*big.Int, *big.Int, error) {
+	curve := group.Curve
+	order := group.TwistOrder()
+
+	var residues, moduli []*big.Int
+	for _, r := range SmallFactors(order, bound) {
+		if new(big.Int).Mod(order, new(big.Int).Mul(r, r)).Sign() == 0 {
+			continue
+		}
+
+		h, err := twistPointOfOrder(curve, order, r)
+		if err != nil {
+			return nil, nil, err
+		}
+		message, mac := bob.Respond(h)
+		b, err := bruteForceLadderMAC(curve, h, r, message, mac)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		residues = append(residues, b)
+		moduli = append(moduli, r)
+	}
+
+	if len(moduli) == 0 {
+		return nil, nil, errors.New("No small factors of the twist order")
+	}
+
+	ref := -1
+	for i, b := range residues {
+		if b.Sign() == 0 {
+			continue
+		}
+		if ref < 0 {
+			ref = i
+			continue
+		}
+
+		h, err := twistPointOfOrder(curve, order, moduli[ref], moduli[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		message, mac := bob.Respond(h)
+
+		c, _, err := cryptopals.CRT(
+			[]*big.Int{residues[ref], b},
+			[]*big.Int{moduli[ref], moduli[i]},
+		)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !hmac.Equal(MAC(curve.Ladder(h, c), message), mac) {
+			b.Sub(moduli[i], b)
+		}
+	}
+
+	return cryptopals.CRT(residues, moduli)
+}
+
+// The point on the Weierstrass form of the curve with u coordinate `u`. It's
+// one of two, since we don't know the sign of v.
+func liftX(curve *ec.MontgomeryCurve, u *big.Int) (*ec.Point, error) {
+	v := curve.V(u)
+	if v == nil {
+		return nil, errors.New("u is not on the curve")
+	}
+	return curve.ToWeierstrassPoint(u, v), nil
+}
+
+// The points on a curve generated by g, written additively, for the
+// kangaroos. They pick jumps by x coordinate.
+type ecKangarooGroup struct {
+	curve *ec.Curve
+	g     *ec.Point
+}
+
+func (e ecKangarooGroup) Exp(x *big.Int) interface{} { return e.curve.ScalarMult(e.g, x) }
+
+func (e ecKangarooGroup) Mul(a, b interface{}) interface{} {
+	return e.curve.Add(a.(*ec.Point), b.(*ec.Point))
+}
+
+func (e ecKangarooGroup) Equal(a, b interface{}) bool { return a.(*ec.Point).Equal(b.(*ec.Point)) }
+
+func (e ecKangarooGroup) Hash(a interface{}) *big.Int {
+	if p := a.(*ec.Point); !p.IsInfinity() {
+		return p.X
+	}
+	return new(big.Int)
+}
+
+// Finds the discrete log of y = x*g for x in [a, b] with Pollard's kangaroo
+// algorithm, in the group of points on `curve`, using `f` to pick the jumps
+func ECKangaroo(curve *ec.Curve, g, y *ec.Point, a, b *big.Int, f *JumpFunction) (*big.Int, error) {
+	return KangarooInGroup(ecKangarooGroup{curve, g}, y, a, b, f)
+}
+
+// Recovers Bob's private key, or its negation, which derives the same shared
+// secrets. The twist gives us x = +-n mod R, and kangaroos over the
+// Weierstrass form of the curve catch the rest for each sign.
+func TwistAttack(bob *LadderBob, group *MontgomeryGroup) (*big.Int, error) {
+	return twistAttack(bob, group, twistFactorBound)
+}
+
+// TwistAttack, brute forcing the twist factors below `bound` and leaving the
+// rest to the kangaroos
+func twistAttack(bob *LadderBob, group *MontgomeryGroup, bound int64) (*big.Int, error) {
+	n, R, err := RecoverTwistResidues(bob, group, bound)
+	if err != nil {
+		return nil, err
+	}
+
+	curve := group.Curve.ToWeierstrass()
+	g, err := liftX(group.Curve, group.U)
+	if err != nil {
+		return nil, err
+	}
+	y, err := liftX(group.Curve, bob.PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	// g' = R*g
+	gR := curve.ScalarMult(g, R)
+
+	// m is in [0, (N-1)/R]
+	max := new(big.Int).Sub(group.N, big1)
+	max.Div(max, R)
+
+	// We don't know the sign of v for either lift, so y is x*g or -x*g. The
+	// log of -x*g is n - x, which isn't +-x mod R, so try both. If the wild
+	// kangaroos all escape, shift m by s and send them out again.
+	f := DefaultJumps(new(big.Int), max)
+	for i := int64(0); i < kangarooTries; i++ {
+		s := big.NewInt(i * kangarooShift)
+		for _, y := range []*ec.Point{y, curve.Neg(y)} {
+			for _, t := range []*big.Int{n, new(big.Int).Sub(R, n)} {
+				// y' = y - t*g + s*g' = (m+s)*g'
+				y1 := curve.Add(y, curve.Neg(curve.ScalarMult(g, t)))
+				y1 = curve.Add(y1, curve.ScalarMult(gR, s))
+
+				m, err := ECKangaroo(curve, gR, y1, s, new(big.Int).Add(max, s), f)
+				if err != nil {
+					continue
+				}
+
+				// x = t + m*R. The kangaroos can also collide for the wrong
+				// sign, so make sure it's really Bob's key.
+				x := m.Sub(m, s)
+				x.Mul(x, R)
+				x.Add(x, t)
+				if group.Curve.Ladder(group.U, x).Cmp(bob.PublicKey()) == 0 {
+					return x, nil
+				}
+			}
+		}
+	}
+
+	return nil, errors.New("The wild kangaroos escaped")
+}
@@ -0,0 +1,168 @@
+package set8
+
+import (
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+)
+
+func TestMontgomeryParams(t *testing.T) {
+	group, err := GetMontgomeryParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := group.Curve.Ladder(group.U, group.N); u.Sign() != 0 {
+		t.Errorf("Expected ladder(4, n) = 0, got %d", u)
+	}
+
+	// The base point is the one from Challenge 59, under the birational map
+	ecGroup, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := liftX(group.Curve, group.U)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.X.Cmp(ecGroup.G.X) != 0 {
+		t.Errorf("u = 4 maps to x = %d, expected %d", g.X, ecGroup.G.X)
+	}
+}
+
+func TestTwistOrder(t *testing.T) {
+	group, err := GetMontgomeryParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := group.Curve.RandomTwistPoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h := group.Curve.Ladder(u, group.TwistOrder()); h.Sign() != 0 {
+		t.Errorf("Wrong order for the twist")
+	}
+}
+
+func TestLadderBob(t *testing.T) {
+	group, err := GetMontgomeryParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewLadderBob(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := NewLadderBob(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Alice's MAC over Bob's public key should match Bob's over hers
+	_, mac := bob.Respond(alice.PublicKey())
+	_, expected := alice.Respond(bob.PublicKey())
+	if string(mac) != string(expected) {
+		t.Errorf("Alice and Bob derived different shared secrets")
+	}
+}
+
+func TestRecoverTwistResidues(t *testing.T) {
+	group, err := GetMontgomeryParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewLadderBob(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Skip the largest factor to keep this quick
+	n, R, err := RecoverTwistResidues(bob, group, 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := new(big.Int).Mod(bob.key, R)
+	if x.Cmp(n) != 0 && x.Cmp(new(big.Int).Sub(R, n)) != 0 {
+		t.Errorf("Expected x = +-%d mod %d, got %d", n, R, x)
+	}
+}
+
+func TestECKangaroo(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x := big.NewInt(987654)
+	y := group.Curve.ScalarMult(group.G, x)
+
+	result, err := ECKangaroo(group.Curve, group.G, y, big.NewInt(0), big.NewInt(1<<20), DefaultJumps(big.NewInt(0), big.NewInt(1<<20)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Cmp(x) != 0 {
+		t.Errorf("Expected %d, got %d", x, result)
+	}
+}
+
+// A toy version of the Challenge 60 curve over a 24-bit prime:
+//
+//	v^2 = u^3 + 35*u^2 + u
+//
+// The curve has 8 * 2097383 points, and the twist has 2^5 * 29 * 18077.
+func toyMontgomeryGroup() *MontgomeryGroup {
+	curve := &ec.MontgomeryCurve{A: big.NewInt(35), B: big.NewInt(1), P: big.NewInt(16777259)}
+	return &MontgomeryGroup{curve, big.NewInt(3488027), big.NewInt(2097383), big.NewInt(16779064)}
+}
+
+// Only brute force the factor 29 on the toy curve, so the kangaroos have to
+// find the rest of the key in a range of about 72,000
+func TestTwistAttackToy(t *testing.T) {
+	group := toyMontgomeryGroup()
+	if u := group.Curve.Ladder(group.U, group.N); u.Sign() != 0 {
+		t.Fatalf("Expected ladder(u, n) = 0, got %d", u)
+	}
+
+	for i := 0; i < 5; i++ {
+		bob, err := NewLadderBob(group)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		x, err := twistAttack(bob, group, 1<<10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u := group.Curve.Ladder(group.U, x); u.Cmp(bob.PublicKey()) != 0 {
+			t.Errorf("Recovered the wrong private key: %d", x)
+		}
+	}
+}
+
+// The real thing takes a couple of minutes, which is more than make test
+// allows. Set CRYPTOPALS_SLOW_TESTS to run it.
+func TestTwistAttack(t *testing.T) {
+	if os.Getenv("CRYPTOPALS_SLOW_TESTS") == "" {
+		t.Skip("Set CRYPTOPALS_SLOW_TESTS to run the full twist attack")
+	}
+
+	group, err := GetMontgomeryParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := NewLadderBob(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	x, err := TwistAttack(bob, group)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u := group.Curve.Ladder(group.U, x); u.Cmp(bob.PublicKey()) != 0 {
+		t.Errorf("Recovered the wrong private key: %d", x)
+	}
+}
@@ -0,0 +1,337 @@
+/*
+ * Duplicate-Signature Key Selection in ECDSA (and RSA)
+ *
+ * Suppose you have a message-signature pair. If I give you a public key that
+ * verifies the signature, can you trust that I'm the author?
+ *
+ * You shouldn't. It turns out to be pretty easy to solve this problem across
+ * a variety of digital signature schemes. If you have a message-signature
+ * pair, you can create a new key that verifies the same signature. This is
+ * called duplicate-signature key selection (DSKS).
+ *
+ * First, implement ECDSA. If you still have your old DSA implementation
+ * lying around, this should be straightforward. All the same, here's a
+ * refresher if you need it:
+ *
+ *     function sign(m, d):
+ *         k := random_scalar(1, n)
+ *         r := (k * G).x
+ *         s := (H(m) + d*r) * k^-1
+ *         return (r, s)
+ *
+ *     function verify(m, (r, s), Q):
+ *         u1 := H(m) * s^-1
+ *         u2 := r * s^-1
+ *         R := u1*G + u2*Q
+ *         return r = R.x
+ *
+ * Here's how to pull off DSKS for ECDSA. Alice has a key pair (d_A, Q_A) and
+ * a signature (r, s) of message m. Eve wants to find a key pair that
+ * verifies the same signature:
+ *
+ *   1. Calculate t := u1 + u2*d_A.
+ *   2. Calculate R := t*G.
+ *   3. Choose a random d' and calculate t := u1 + u2*d'.
+ *   4. Calculate G' := t^-1 * R.
+ *   5. Calculate Q' := d'*G'.
+ *   6. Eve's public key is Q', on the curve with base point G'.
+ *
+ * Of course, Eve doesn't know d_A, but she doesn't need it: R is just
+ * u1*G + u2*Q_A.
+ *
+ * RSA is a little harder. Given s = pad(m)^d mod N, Eve wants a new key
+ * (e', N') such that s^e' = pad(m) mod N'. That's a discrete log problem,
+ * so she'll pick N' = p*q where it's easy: p-1 and q-1 should both be
+ * smooth, and s should generate the whole group mod p and mod q. Solve for
+ * e' mod p-1 and e' mod q-1 with Pohlig-Hellman, and use the CRT to put them
+ * together. Make sure p-1 and q-1 don't share any factors other than 2, or
+ * the CRT might not work out.
+ */
+
+package set8
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+	"github.com/DavidWittman/cryptopals-challenge/set6"
+)
+
+// The largest prime factor we'll allow in p-1 for the RSA primes
+const smoothBound = 1 << 12
+
+// Finds a new ECDSA key pair, on the same curve but with a different base
+// point, which verifies the signature `sig` of `hash` under `pub`
+func ECDSADuplicateKey(group *ec.Group, pub *ec.Point, hash []byte, sig *ec.Signature) (*ec.PrivateKey, error) {
+	c, n := group.Curve, group.N
+
+	// R = u1*G + u2*Q
+	w := new(big.Int).ModInverse(sig.S, n)
+	u1 := new(big.Int).Mul(ec.HashToInt(hash, n), w)
+	u1.Mod(u1, n)
+	u2 := new(big.Int).Mul(sig.R, w)
+	u2.Mod(u2, n)
+	R := c.Add(c.ScalarMult(group.G, u1), c.ScalarMult(pub, u2))
+
+	for {
+		// d' = random in [1, n)
+		d, err := rand.Int(rand.Reader, new(big.Int).Sub(n, big1))
+		if err != nil {
+			return nil, err
+		}
+		d.Add(d, big1)
+
+		// t = u1 + u2*d'
+		t := new(big.Int).Mul(u2, d)
+		t.Add(t, u1)
+		t.Mod(t, n)
+		if t.Sign() == 0 {
+			continue
+		}
+
+		// G' = t^-1 * R, Q' = d'*G'
+		G := c.ScalarMult(R, t.ModInverse(t, n))
+		forged := &ec.Group{Curve: c, G: G, N: n}
+		return &ec.PrivateKey{Group: forged, D: d, Public: c.ScalarMult(G, d)}, nil
+	}
+}
+
+// An RSA key pair whose public exponent can be as big as the modulus, which
+// doesn't fit in an rsa.PublicKey
+type RSAKeyPair struct {
+	N, E, D *big.Int
+}
+
+// Verifies a PKCS#1 v1.5 SHA-1 signature of `message`, like set6.RSAVerify
+func (k *RSAKeyPair) Verify(message, sig []byte) bool {
+	s := new(big.Int).SetBytes(sig)
+	if s.Cmp(k.N) >= 0 {
+		return false
+	}
+
+	hashed := sha1.Sum(message)
+	expected := set6.PKCS1v15SignaturePad(hashed[:], (k.N.BitLen()+7)/8)
+	return s.Exp(s, k.E, k.N).Cmp(new(big.Int).SetBytes(expected)) == 0
+}
+
+// The primes in [min, max), with the sieve of Eratosthenes
+func smallPrimes(min, max int64) []*big.Int {
+	composite := make([]bool, max)
+	var primes []*big.Int
+	for i := int64(2); i < max; i++ {
+		if composite[i] {
+			continue
+		}
+		if i >= min {
+			primes = append(primes, big.NewInt(i))
+		}
+		for j := i * i; j < max; j += i {
+			composite[j] = true
+		}
+	}
+	return primes
+}
+
+// A random element of `primes` which isn't in `avoid`, or nil if they're all
+// in it
+func randomPrime(primes []*big.Int, avoid map[int64]bool) (*big.Int, error) {
+	var candidates []*big.Int
+	for _, r := range primes {
+		if !avoid[r.Int64()] {
+			candidates = append(candidates, r)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(candidates))))
+	if err != nil {
+		return nil, err
+	}
+	return candidates[i.Int64()], nil
+}
+
+// Finds a random prime p in [lo, hi) where p-1 = 2 * r1 * r2 * ... for
+// distinct primes from `primes`, none of which are in `avoid`. Returns p and
+// the prime factors of p-1.
+//
+// We pick random factors until the product is close to lo, then the last one
+// has to be in the right range for p to land in [lo, hi).
+func SmoothPrime(lo, hi *big.Int, primes []*big.Int, avoid map[int64]bool) (*big.Int, []*big.Int, error) {
+	largest := primes[len(primes)-1]
+
+	for {
+		factors := []*big.Int{big.NewInt(2)}
+		used := map[int64]bool{2: true}
+		for k := range avoid {
+			used[k] = true
+		}
+
+		prod := big.NewInt(2)
+		for new(big.Int).Mul(prod, largest).Cmp(lo) < 0 {
+			r, err := randomPrime(primes, used)
+			if err != nil {
+				return nil, nil, err
+			}
+			if r == nil {
+				return nil, nil, errors.New("Not enough small primes")
+			}
+			used[r.Int64()] = true
+			factors = append(factors, r)
+			prod.Mul(prod, r)
+		}
+
+		// (lo - 1) / prod <= r <= (hi - 2) / prod, rounding the minimum up
+		min := new(big.Int).Sub(lo, big1)
+		min.Add(min, prod)
+		min.Sub(min, big1)
+		min.Div(min, prod)
+		max := new(big.Int).Sub(hi, big.NewInt(2))
+		max.Div(max, prod)
+
+		i := sort.Search(len(primes), func(i int) bool { return primes[i].Cmp(min) >= 0 })
+		j := sort.Search(len(primes), func(i int) bool { return primes[i].Cmp(max) > 0 })
+		r, err := randomPrime(primes[i:j], used)
+		if err != nil {
+			return nil, nil, err
+		}
+		if r == nil {
+			continue
+		}
+
+		p := new(big.Int).Mul(prod, r)
+		p.Add(p, big1)
+		if p.ProbablyPrime(20) {
+			return p, append(factors, r), nil
+		}
+	}
+}
+
+// Whether g generates the whole group mod p, where `factors` are the prime
+// factors of p-1
+func isPrimitiveRoot(g, p *big.Int, factors []*big.Int) bool {
+	pMinus1 := new(big.Int).Sub(p, big1)
+	for _, r := range factors {
+		exp := new(big.Int).Div(pMinus1, r)
+		if new(big.Int).Exp(g, exp, p).Cmp(big1) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Finds the discrete log of y = g^x mod p with the Pohlig-Hellman algorithm,
+// where `factors` are the distinct prime factors of p-1 and p-1 is squarefree.
+// For each factor r, we brute force x mod r in the subgroup of order r.
+func PohligHellman(g, y, p *big.Int, factors []*big.Int) (*big.Int, error) {
+	pMinus1 := new(big.Int).Sub(p, big1)
+
+	var residues []*big.Int
+	for _, r := range factors {
+		exp := new(big.Int).Div(pMinus1, r)
+		gr := new(big.Int).Exp(g, exp, p)
+		yr := new(big.Int).Exp(y, exp, p)
+
+		var x *big.Int
+		K := big.NewInt(1)
+		for k := int64(0); k < r.Int64(); k++ {
+			if K.Cmp(yr) == 0 {
+				x = big.NewInt(k)
+				break
+			}
+			K.Mul(K, gr)
+			K.Mod(K, p)
+		}
+		if x == nil {
+			return nil, errors.New("y is not in the subgroup generated by g")
+		}
+		residues = append(residues, x)
+	}
+
+	x, _, err := cryptopals.CRT(residues, factors)
+	return x, err
+}
+
+// Finds a prime p in [lo, hi) with a smooth p-1 where s is a primitive root,
+// and solves for x = log_s(m) mod p-1
+func smoothDiscreteLog(s, m, lo, hi *big.Int, primes []*big.Int, avoid map[int64]bool) (p *big.Int, factors []*big.Int, x *big.Int, err error) {
+	for {
+		p, factors, err = SmoothPrime(lo, hi, primes, avoid)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		if !isPrimitiveRoot(s, p, factors) {
+			continue
+		}
+		if x, err = PohligHellman(s, new(big.Int).Mod(m, p), p, factors); err == nil {
+			return p, factors, x, nil
+		}
+	}
+}
+
+// Finds a new RSA key pair which verifies the PKCS#1 v1.5 signature `sig` of
+// `message` under `pub`. The new modulus is the same length as N and larger
+// than it, so the padded message and signature look just the same.
+func RSADuplicateKey(pub *rsa.PublicKey, message, sig []byte) (*RSAKeyPair, error) {
+	bits := pub.N.BitLen()
+	hashed := sha1.Sum(message)
+	m := new(big.Int).SetBytes(set6.PKCS1v15SignaturePad(hashed[:], (bits+7)/8))
+	s := new(big.Int).SetBytes(sig)
+
+	primes := smallPrimes(3, smoothBound)
+
+	// p is in [2^(pBits-1), 2^pBits)
+	pBits := (bits + 1) / 2
+	pLo := new(big.Int).Lsh(big1, uint(pBits-1))
+	pHi := new(big.Int).Lsh(big1, uint(pBits))
+
+	// p*q has to be in (N, 2^bits)
+	nHi := new(big.Int).Lsh(big1, uint(bits))
+
+	for {
+		p, pFactors, ep, err := smoothDiscreteLog(s, m, pLo, pHi, primes, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		// q-1 can't share any odd factors with p-1
+		avoid := make(map[int64]bool)
+		for _, r := range pFactors {
+			avoid[r.Int64()] = true
+		}
+
+		qLo := new(big.Int).Div(pub.N, p)
+		qLo.Add(qLo, big1)
+		qHi := new(big.Int).Div(nHi, p)
+		q, _, eq, err := smoothDiscreteLog(s, m, qLo, qHi, primes, avoid)
+		if err != nil {
+			return nil, err
+		}
+
+		// e' = ep mod p-1, e' = eq mod q-1. They both include a factor of 2,
+		// so we need the generalized CRT, and ep and eq need the same parity.
+		e, _, err := cryptopals.GeneralizedCRT(
+			[]*big.Int{ep, eq},
+			[]*big.Int{new(big.Int).Sub(p, big1), new(big.Int).Sub(q, big1)},
+		)
+		if err != nil {
+			continue
+		}
+
+		// e' also needs an inverse mod the totient, which it won't have if
+		// it's even
+		n, d, err := set_five.RSAKeyFromPrimes(p, q, e)
+		if err != nil {
+			continue
+		}
+
+		return &RSAKeyPair{n, e, d}, nil
+	}
+}
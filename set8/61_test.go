@@ -0,0 +1,132 @@
+package set8
+
+import (
+	"crypto/sha256"
+	"math/big"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+	"github.com/DavidWittman/cryptopals-challenge/set5"
+	"github.com/DavidWittman/cryptopals-challenge/set6"
+)
+
+func TestECDSADuplicateKey(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err := ec.GenerateKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte(MESSAGE_57))
+	sig, err := alice.Sign(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	eve, err := ECDSADuplicateKey(group, alice.Public, hash[:], sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eve.Public.Equal(alice.Public) {
+		t.Errorf("Eve ended up with Alice's public key")
+	}
+	if !ec.Verify(eve.Group, eve.Public, hash[:], sig) {
+		t.Errorf("Alice's signature doesn't verify under Eve's key")
+	}
+
+	// Eve's key is a real key pair, and can make signatures of its own
+	other := sha256.Sum256([]byte("a different message"))
+	eveSig, err := eve.Sign(other[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ec.Verify(eve.Group, eve.Public, other[:], eveSig) {
+		t.Errorf("Eve's own signature doesn't verify")
+	}
+}
+
+func TestSmoothPrime(t *testing.T) {
+	primes := smallPrimes(3, smoothBound)
+	lo := new(big.Int).Lsh(big1, 255)
+	hi := new(big.Int).Lsh(big1, 256)
+
+	p, factors, err := SmoothPrime(lo, hi, primes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.BitLen() != 256 || !p.ProbablyPrime(20) {
+		t.Errorf("Expected a 256 bit prime, got %d", p)
+	}
+
+	product := big.NewInt(1)
+	for _, r := range factors {
+		product.Mul(product, r)
+	}
+	if product.Add(product, big1).Cmp(p) != 0 {
+		t.Errorf("The factors of p-1 are wrong")
+	}
+}
+
+func TestPohligHellman(t *testing.T) {
+	primes := smallPrimes(3, smoothBound)
+	lo := new(big.Int).Lsh(big1, 127)
+	hi := new(big.Int).Lsh(big1, 128)
+
+	for {
+		p, factors, err := SmoothPrime(lo, hi, primes, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		g := big.NewInt(3)
+		if !isPrimitiveRoot(g, p, factors) {
+			continue
+		}
+
+		y := new(big.Int).Exp(g, big.NewInt(123456789), p)
+		x, err := PohligHellman(g, y, p, factors)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if x.Int64() != 123456789 {
+			t.Errorf("Expected 123456789, got %d", x)
+		}
+		return
+	}
+}
+
+func TestRSADuplicateKey(t *testing.T) {
+	key, err := set_five.RSAGenerate(1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	message := []byte(MESSAGE_57)
+	sig := set6.RSASign(message, key)
+	if !set6.RSAVerify(message, sig, &key.PublicKey) {
+		t.Fatal("The original signature doesn't verify")
+	}
+
+	eve, err := RSADuplicateKey(&key.PublicKey, message, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if eve.N.Cmp(key.N) == 0 {
+		t.Errorf("Eve ended up with the same modulus")
+	}
+	if !eve.Verify(message, sig) {
+		t.Errorf("The signature doesn't verify under Eve's key")
+	}
+	if eve.Verify([]byte("a different message"), sig) {
+		t.Errorf("The signature verifies for the wrong message")
+	}
+
+	// Eve's private key works too
+	m := big.NewInt(42)
+	c := new(big.Int).Exp(m, eve.E, eve.N)
+	if c.Exp(c, eve.D, eve.N).Cmp(m) != 0 {
+		t.Errorf("Eve's private key doesn't invert her public key")
+	}
+}
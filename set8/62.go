@@ -0,0 +1,166 @@
+/*
+ * Key-Recovery Attacks on ECDSA with Biased Nonces
+ *
+ * Back in set 6 we saw how "nonce" is kind of a misnomer for the k value in
+ * DSA. It's really more like an ephemeral key. And distressingly, the
+ * security of your long-term private key hinges on it.
+ *
+ * Nonce disclosure? Congrats, you just coughed up your secret key.
+ *
+ * Predictable nonce? Ditto.
+ *
+ * Even by repeating a nonce you lose everything.
+ *
+ * How far can we take this? Turns out, pretty far: even a slight bias in
+ * nonce generation is enough for an attacker to recover your private key.
+ * Let's see how.
+ *
+ * First, let's clarify what we mean by a "biased" nonce. In this case, we're
+ * going to zero out the last eight bits of each nonce. Just eight bits!
+ *
+ * Start by rearranging the signing equation:
+ *
+ *     s = (H(m) + d*r) / k
+ *     k = (H(m) + d*r) / s
+ *
+ * and since the low l bits of k are zero, k = 2^l * b for some b < q/2^l:
+ *
+ *     b = (H(m) + d*r) / (s*2^l)
+ *       = d*t - u
+ *
+ * where t = r / (s*2^l) and u = H(m) / (-s*2^l). So for every signature, d*t
+ * - u is a small number mod q. This is an instance of the hidden number
+ * problem, and we can solve it with lattices. Collect a bunch of t and u
+ * values and put them in a basis like this:
+ *
+ *     b1   = [  q   0   0  ...   0   0   0 ]
+ *     b2   = [  0   q   0  ...   0   0   0 ]
+ *     ...
+ *     bn   = [  0   0   0  ...   q   0   0 ]
+ *     bt   = [ t1  t2  t3  ...  tn  ct   0 ]
+ *     bu   = [ u1  u2  u3  ...  un   0  cu ]
+ *
+ * with ct = 1/2^l and cu = q/2^l. The lattice contains the vector
+ *
+ *     d*bt - bu + sum(k_i * b_i) = [ b1 b2 ... bn d/2^l -cu ]
+ *
+ * which is very short, and LLL should find it for us. Look through the
+ * reduced basis for a vector ending in cu (or -cu, if it's negated), and
+ * multiply its second-to-last entry by 2^l to get d.
+ *
+ * Implement LLL, generate signatures with biased nonces, and recover the
+ * private key. You'll need about 20 signatures.
+ */
+
+package set8
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/lll"
+)
+
+// A hash and the signature over it
+type SignedHash struct {
+	Hash []byte
+	Sig  *ec.Signature
+}
+
+// Signs `hash` with a nonce whose low `bits` bits are all zero
+func BiasedSign(key *ec.PrivateKey, hash []byte, bits uint) (*ec.Signature, error) {
+	n := key.Group.N
+	mask := new(big.Int).Lsh(big1, bits)
+	mask.Sub(mask, big1)
+
+	for {
+		k, err := rand.Int(rand.Reader, n)
+		if err != nil {
+			return nil, err
+		}
+		k.AndNot(k, mask)
+		if k.Sign() == 0 {
+			continue
+		}
+
+		sig := key.SignWithNonce(hash, k)
+		if sig.R.Sign() != 0 && sig.S.Sign() != 0 {
+			return sig, nil
+		}
+	}
+}
+
+// Builds the hidden number problem lattice for the signatures:
+//
+//	t = r / (s*2^l)
+//	u = H(m) / (-s*2^l)
+func biasedNonceBasis(n *big.Int, sigs []SignedHash, bits uint) []lll.Vector {
+	size := len(sigs) + 2
+	basis := make([]lll.Vector, size)
+	for i := range basis {
+		basis[i] = make(lll.Vector, size)
+		for j := range basis[i] {
+			basis[i][j] = new(big.Rat)
+		}
+	}
+
+	twoL := new(big.Int).Lsh(big1, bits)
+	bt, bu := basis[size-2], basis[size-1]
+
+	for i, signed := range sigs {
+		basis[i][i].SetInt(n)
+
+		// 1 / (s*2^l)
+		inv := new(big.Int).Mul(signed.Sig.S, twoL)
+		inv.ModInverse(inv, n)
+
+		t := new(big.Int).Mul(signed.Sig.R, inv)
+		bt[i].SetInt(t.Mod(t, n))
+
+		u := new(big.Int).Mul(ec.HashToInt(signed.Hash, n), inv)
+		u.Neg(u)
+		bu[i].SetInt(u.Mod(u, n))
+	}
+
+	// ct = 1/2^l, cu = q/2^l
+	bt[size-2].SetFrac(big1, twoL)
+	bu[size-1].SetFrac(n, twoL)
+
+	return basis
+}
+
+// Recovers the private key behind `pub` from signatures made with nonces
+// whose low `bits` bits are zero, by solving the hidden number problem with
+// LLL
+func RecoverBiasedNonceKey(group *ec.Group, pub *ec.Point, sigs []SignedHash, bits uint) (*big.Int, error) {
+	n := group.N
+	size := len(sigs) + 2
+	basis := biasedNonceBasis(n, sigs, bits)
+	cu := basis[size-1][size-1]
+	twoL := new(big.Rat).SetInt(new(big.Int).Lsh(big1, bits))
+
+	for _, v := range lll.Reduce(basis, lll.DefaultDelta) {
+		// d*bt - bu ends in -cu, or cu if LLL negated it
+		last := v[size-1]
+		if new(big.Rat).Abs(last).Cmp(cu) != 0 {
+			continue
+		}
+
+		d := new(big.Rat).Mul(v[size-2], twoL)
+		if last.Sign() > 0 {
+			d.Neg(d)
+		}
+		if !d.IsInt() {
+			continue
+		}
+
+		x := new(big.Int).Mod(d.Num(), n)
+		if group.Curve.ScalarMult(group.G, x).Equal(pub) {
+			return x, nil
+		}
+	}
+
+	return nil, errors.New("No vector in the reduced basis gives the private key")
+}
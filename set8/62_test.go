@@ -0,0 +1,68 @@
+package set8
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/ec"
+)
+
+func TestBiasedSign(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ec.GenerateKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hash := sha256.Sum256([]byte(MESSAGE_57))
+	sig, err := BiasedSign(key, hash[:], 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ec.Verify(group, key.Public, hash[:], sig) {
+		t.Fatal("Signature did not verify")
+	}
+
+	// k = (H(m) + d*r) / s should have its low 8 bits cleared
+	k := new(big.Int).Mul(key.D, sig.R)
+	k.Add(k, ec.HashToInt(hash[:], group.N))
+	k.Mul(k, new(big.Int).ModInverse(sig.S, group.N))
+	k.Mod(k, group.N)
+	if k.Uint64()&0xff != 0 {
+		t.Errorf("The nonce %x isn't biased", k)
+	}
+}
+
+func TestRecoverBiasedNonceKey(t *testing.T) {
+	group, err := GetECParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := ec.GenerateKey(group)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sigs []SignedHash
+	for i := 0; i < 22; i++ {
+		hash := sha256.Sum256([]byte(fmt.Sprintf("message %d", i)))
+		sig, err := BiasedSign(key, hash[:], 8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sigs = append(sigs, SignedHash{hash[:], sig})
+	}
+
+	d, err := RecoverBiasedNonceKey(group, key.Public, sigs, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Cmp(key.D) != 0 {
+		t.Errorf("Recovered the wrong private key: %d", d)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+ * Key-Recovery Attacks on GCM with Repeated Nonces
+ *
+ * GCM is the most widely used block cipher mode in TLS today. It combines
+ * CTR-mode encryption with a Carter-Wegman MAC, GHASH, computed over
+ * GF(2^128):
+ *
+ *     t = GHASH_H(A, C) + s
+ *       = b_1*h^n + b_2*h^(n-1) + ... + b_n*h + s
+ *
+ * where b_1..b_n are the blocks of the additional data A, the ciphertext C
+ * and a final block with their lengths, h = E(K, 0^128) is the
+ * authentication key and s = E(K, J0) is a mask derived from the nonce.
+ *
+ * Implement GCM. Elements of GF(2^128) are polynomials with coefficients in
+ * GF(2), reduced mod x^128 + x^7 + x^2 + x + 1, and you'll need to be able
+ * to add, multiply and invert them.
+ *
+ * Now suppose the nonce is repeated. Then s is repeated too, and for two
+ * messages under the same nonce:
+ *
+ *     t1 = GHASH_h(A1, C1) + s
+ *     t2 = GHASH_h(A2, C2) + s
+ *
+ * Add them together and s cancels out, leaving a polynomial in h with all
+ * known coefficients:
+ *
+ *     f(y) = GHASH_y(A1, C1) + t1 + GHASH_y(A2, C2) + t2
+ *
+ * and h is one of its roots. To find them, factor f over GF(2^128): first
+ * make it square-free, then split it into the products of irreducible
+ * factors of each degree, then split those into the factors themselves. The
+ * roots are the linear factors.
+ *
+ * There may be a few candidates. Use another pair of messages under the
+ * same nonce to whittle them down to one. Then recover s = t1 -
+ * GHASH_h(A1, C1), and forge a valid tag for whatever ciphertext you like.
+ */
+
+package set8
+
+import (
+	"errors"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/gf128"
+)
+
+// A GCM ciphertext and tag, with the additional data it authenticates
+type GCMMessage struct {
+	AD         []byte
+	Ciphertext []byte
+	Tag        []byte
+}
+
+// Splits the output of Seal into the ciphertext and tag
+func SplitGCM(ad, sealed []byte, tagSize int) GCMMessage {
+	return GCMMessage{
+		AD:         ad,
+		Ciphertext: sealed[:len(sealed)-tagSize],
+		Tag:        sealed[len(sealed)-tagSize:],
+	}
+}
+
+// GHASH_y(A, C) + t as a polynomial in y
+func gcmPoly(m GCMMessage) gf128.Poly {
+	blocks := cryptopals.GHASHBlocks(m.AD, m.Ciphertext)
+	n := len(blocks)
+
+	coefficients := make([]gf128.Element, n+1)
+	coefficients[0] = gf128.FromBytes(m.Tag)
+	for i, b := range blocks {
+		coefficients[n-i] = b
+	}
+	return gf128.NewPoly(coefficients...)
+}
+
+// Finds the candidates for the authentication key from two messages
+// encrypted under the same key and nonce
+func GCMAuthKeyCandidates(a, b GCMMessage) ([]gf128.Element, error) {
+	f := gcmPoly(a).Add(gcmPoly(b))
+	if f.Degree() < 1 {
+		return nil, errors.New("The messages give a constant polynomial")
+	}
+	return f.Roots()
+}
+
+// Recovers the authentication key from at least two messages encrypted under
+// the same key and nonce. Each message after the second rules out more
+// candidates.
+func RecoverGCMAuthKey(msgs []GCMMessage) (gf128.Element, error) {
+	if len(msgs) < 2 {
+		return gf128.Element{}, errors.New("Need at least two messages")
+	}
+
+	candidates, err := GCMAuthKeyCandidates(msgs[0], msgs[1])
+	if err != nil {
+		return gf128.Element{}, err
+	}
+
+	for _, m := range msgs[2:] {
+		if len(candidates) == 1 {
+			break
+		}
+		var remaining []gf128.Element
+		for _, h := range candidates {
+			if gcmPoly(msgs[0]).Add(gcmPoly(m)).Eval(h).IsZero() {
+				remaining = append(remaining, h)
+			}
+		}
+		candidates = remaining
+	}
+
+	switch len(candidates) {
+	case 0:
+		return gf128.Element{}, errors.New("No candidates for the authentication key")
+	case 1:
+		return candidates[0], nil
+	default:
+		return gf128.Element{}, errors.New("More than one candidate for the authentication key")
+	}
+}
+
+// Forges a tag for any ciphertext under the nonce of `known`, given the
+// authentication key
+func ForgeGCMTag(h gf128.Element, known GCMMessage, ad, ciphertext []byte) []byte {
+	// s = t - GHASH_h(A, C)
+	s := gf128.FromBytes(known.Tag).Add(cryptopals.GHASH(h, known.AD, known.Ciphertext))
+	return cryptopals.GHASH(h, ad, ciphertext).Add(s).Bytes()
+}
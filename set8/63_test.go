@@ -0,0 +1,47 @@
+package set8
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+func TestGCMForbiddenAttack(t *testing.T) {
+	key, _ := cryptopals.GenerateRandomBytes(16)
+	nonce, _ := cryptopals.GenerateRandomBytes(12)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := cryptopals.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []GCMMessage
+	for _, plaintext := range []string{
+		"Our nonce generator has a bug",
+		"and it keeps handing out the same one",
+		"which is a shame for GCM",
+	} {
+		ad := []byte("header")
+		sealed := g.Seal(nil, nonce, []byte(plaintext), ad)
+		msgs = append(msgs, SplitGCM(ad, sealed, g.Overhead()))
+	}
+
+	h, err := RecoverGCMAuthKey(msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h != g.(cryptopals.GCMAuthKeyer).AuthKey() {
+		t.Fatalf("Recovered the wrong authentication key: %x", h.Bytes())
+	}
+
+	ad := []byte("forged header")
+	ciphertext := []byte("any ciphertext we like, of any length")
+	tag := ForgeGCMTag(h, msgs[0], ad, ciphertext)
+	if _, err := g.Open(nil, nonce, append(ciphertext, tag...), ad); err != nil {
+		t.Errorf("The forged tag was rejected: %s", err)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+ * Key-Recovery Attacks on GCM with a Truncated MAC
+ *
+ * This one is my favorite.
+ *
+ * It's also a little more involved than the last one, so grab a coffee. GCM
+ * lets you truncate the tag, and people do: 32 bits isn't unheard of. The
+ * usual intuition is that this costs you forgery resistance and nothing
+ * else. That's wrong, and it costs you the authentication key.
+ *
+ * Start from a valid ciphertext and tag with 2^n blocks. We're going to
+ * change the ciphertext without changing the tag and hope the oracle still
+ * accepts it. Changing the block that multiplies h^k by e_k changes the
+ * GHASH by
+ *
+ *     e = sum(e_k * h^k)
+ *
+ * which is a polynomial in h, so it's hard to control. But squaring is
+ * linear in GF(2^128), so if we only touch the blocks multiplying h^(2^i):
+ *
+ *     e = sum(d_i * h^(2^i))
+ *
+ * and that's a linear function of h. Write it as a matrix over GF(2):
+ *
+ *     e = Ad * h,    Ad = sum(M_d_i * Ms^i)
+ *
+ * where M_c is the matrix for multiplying by c and Ms is the matrix for
+ * squaring. The forgery succeeds when the first 32 bits of e are zero.
+ *
+ * Each entry of Ad is a linear function of the bits of d, so we can build a
+ * matrix T that maps d to the first few rows of Ad. Find its kernel, and any
+ * d in there zeroes those rows no matter what h is. With n*128 bits of d and
+ * 128 bits in each row, we can zero out n-1 rows, and the forgery succeeds
+ * with probability 2^-(32-(n-1)).
+ *
+ * When one does succeed, we learn that the rest of the first 32 rows of Ad
+ * dotted with h are zero too. Those are linear equations in h. Collect them
+ * in a matrix K, and let X be a basis for its kernel: h = X*h' for some
+ * unknown h'. Now we only need Ad*X to have zero rows, and X has fewer
+ * columns, so we can zero more rows and forge more often. Keep going until
+ * K has rank 127 and its kernel is h itself.
+ *
+ * Use a 32-bit tag and 2^17 blocks if you've got time to kill. The same
+ * attack works at any size.
+ */
+
+package set8
+
+import (
+	"errors"
+	"math/rand"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/gf128"
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals/gf2"
+)
+
+// Reports whether a modified ciphertext is accepted with the original tag
+type ForgeryOracle func(ciphertext []byte) bool
+
+// The ciphertext blocks multiplying h^(2^i), i = 1..n. The last block
+// multiplies h^2, since the length block comes after it.
+func powerOfTwoBlocks(blocks int) []int {
+	var indexes []int
+	for k := 2; k-1 <= blocks; k *= 2 {
+		indexes = append(indexes, blocks-(k-1))
+	}
+	return indexes
+}
+
+// Builds the matrix T mapping the bits of d to the first `rows` rows of
+// Ad*X. The entry of Ad*X in row r and column j, as a function of the bits of
+// d_i, is row r of d_i * x_j^(2^i), where x_j is column j of X.
+func truncatedMACMatrix(x *gf2.Matrix, n, rows int) *gf2.Matrix {
+	t := gf2.NewMatrix(rows*x.Cols, n*128)
+
+	for j := 0; j < x.Cols; j++ {
+		y := gf2.VectorElement(x.Column(j))
+		for i := 0; i < n; i++ {
+			y = y.Square()
+			for b := 0; b < 128; b++ {
+				// Setting bit b of d_i multiplies by x^b
+				basis := gf2.NewVector(128)
+				basis.SetBit(b, 1)
+				product := gf2.ElementVector(gf2.VectorElement(basis).Mul(y))
+				for r := 0; r < rows; r++ {
+					t.Set(r*x.Cols+j, i*128+b, product.Bit(r))
+				}
+			}
+		}
+	}
+
+	return t
+}
+
+// Ad = sum(M_d_i * Ms^i)
+func errorMatrix(d gf2.Vector, n int) *gf2.Matrix {
+	ms := gf2.SquareMatrix()
+	msi := gf2.Identity(128)
+	ad := gf2.NewMatrix(128, 128)
+
+	for i := 0; i < n; i++ {
+		msi = ms.Mul(msi)
+		di := gf2.NewVector(128)
+		for b := 0; b < 128; b++ {
+			di.SetBit(b, d.Bit(i*128+b))
+		}
+		ad = ad.Add(gf2.MulMatrix(gf2.VectorElement(di)).Mul(msi))
+	}
+
+	return ad
+}
+
+// Flips the bits of d into the power of two blocks of the ciphertext
+func applyFlips(ciphertext []byte, indexes []int, d gf2.Vector) []byte {
+	forged := append([]byte{}, ciphertext...)
+	for i, index := range indexes {
+		block := forged[index*gf128.Size : (index+1)*gf128.Size]
+		for b, c := range gf2.VectorElement(d[2*i : 2*i+2]).Bytes() {
+			block[b] ^= c
+		}
+	}
+	return forged
+}
+
+// Recovers the GCM authentication key from an oracle which accepts or
+// rejects modified versions of `ciphertext` under a tag truncated to
+// `tagBits` bits
+func TruncatedMACAttack(oracle ForgeryOracle, ciphertext []byte, tagBits int) (gf128.Element, error) {
+	if len(ciphertext)%gf128.Size != 0 {
+		return gf128.Element{}, errors.New("The ciphertext must be a whole number of blocks")
+	}
+	indexes := powerOfTwoBlocks(len(ciphertext) / gf128.Size)
+	n := len(indexes)
+
+	// Equations we've learned about h, and a basis for the solutions
+	k := gf2.NewMatrix(0, 128)
+	x := gf2.Identity(128)
+
+	for x.Cols > 1 {
+		// Zero out as many rows of Ad*X as we can while still leaving some
+		// of the tag to learn from
+		rows := (n*128 - 1) / x.Cols
+		if rows >= tagBits {
+			rows = tagBits - 1
+		}
+
+		kernel := truncatedMACMatrix(x, n, rows).Kernel()
+		if kernel.Cols == 0 {
+			return gf128.Element{}, errors.New("No bit flips zero out the error")
+		}
+
+		for {
+			combination := gf2.NewVector(kernel.Cols)
+			for i := 0; i < kernel.Cols; i++ {
+				combination.SetBit(i, uint(rand.Intn(2)))
+			}
+			d := kernel.MulVector(combination)
+			if d.IsZero() || !oracle(applyFlips(ciphertext, indexes, d)) {
+				continue
+			}
+
+			// The rest of the tag rows of Ad*h are zero too
+			ad := errorMatrix(d, n)
+			for r := rows; r < tagBits; r++ {
+				k.AppendRow(ad.Row(r))
+			}
+			x = k.Kernel()
+			break
+		}
+	}
+
+	if x.Cols == 0 {
+		return gf128.Element{}, errors.New("The equations for h have no solution")
+	}
+	return gf2.VectorElement(x.Column(0)), nil
+}
@@ -0,0 +1,60 @@
+package set8
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/DavidWittman/cryptopals-challenge/cryptopals"
+)
+
+func TestPowerOfTwoBlocks(t *testing.T) {
+	// Block j of 8 multiplies h^(9-j), so blocks 7, 5 and 1 multiply h^2,
+	// h^4 and h^8
+	expected := []int{7, 5, 1}
+	indexes := powerOfTwoBlocks(8)
+	if len(indexes) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, indexes)
+	}
+	for i := range expected {
+		if indexes[i] != expected[i] {
+			t.Errorf("Expected %v, got %v", expected, indexes)
+		}
+	}
+}
+
+func TestTruncatedMACAttack(t *testing.T) {
+	// A 16-bit tag and 2^9 blocks keep the test quick. The challenge's 32-bit
+	// tag and 2^17 blocks work too, but take a lot longer.
+	const tagSize = 2
+
+	key, _ := cryptopals.GenerateRandomBytes(16)
+	nonce, _ := cryptopals.GenerateRandomBytes(12)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := cryptopals.NewGCMWithTagSize(block, tagSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext, _ := cryptopals.GenerateRandomBytes(512 * 16)
+	sealed := g.Seal(nil, nonce, plaintext, nil)
+	msg := SplitGCM(nil, sealed, tagSize)
+
+	queries := 0
+	oracle := func(ciphertext []byte) bool {
+		queries++
+		_, err := g.Open(nil, nonce, append(ciphertext, msg.Tag...), nil)
+		return err == nil
+	}
+
+	h, err := TruncatedMACAttack(oracle, msg.Ciphertext, tagSize*8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Logf("Recovered h after %d queries", queries)
+	if h != g.(cryptopals.GCMAuthKeyer).AuthKey() {
+		t.Errorf("Recovered the wrong authentication key: %x", h.Bytes())
+	}
+}